@@ -0,0 +1,217 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultLeafWetnessRH is the relative humidity (%) at or above which foliage is
+// commonly assumed to be wet.
+const DefaultLeafWetnessRH float32 = 90
+
+func isLeafWet(rec HourlyWeatherData, rhThreshold float32) bool {
+	humid := !IsMissing(rec.RelativeHumidity) && rec.RelativeHumidity >= rhThreshold
+	raining := !IsMissing(rec.Precipitation) && rec.Precipitation > 0
+	return humid || raining
+}
+
+// LeafWetnessHours estimates leaf wetness duration as the number of hours with
+// relative humidity at or above rhThreshold or with measurable precipitation.
+// Missing observations never make an hour wet.
+func LeafWetnessHours(data []HourlyWeatherData, rhThreshold float32) int {
+	hours := 0
+	for _, rec := range data {
+		if isLeafWet(rec, rhThreshold) {
+			hours++
+		}
+	}
+	return hours
+}
+
+// LeafWetnessHoursByDay is LeafWetnessHours broken out by day of year.
+func LeafWetnessHoursByDay(data []HourlyWeatherData, rhThreshold float32) map[int]int {
+	days := make(map[int]int)
+	for _, rec := range data {
+		if isLeafWet(rec, rhThreshold) {
+			days[rec.Day]++
+		}
+	}
+	return days
+}
+
+// Thresholds used by IsInversionLikely.
+const (
+	inversionMaxSolarRadiation float32 = 0.01 // MJ/m², treated as night
+	inversionMaxWindSpeed      float32 = 2    // m/s
+)
+
+// IsInversionLikely flags conditions that favor a near-surface temperature
+// inversion: a night-time hour with light wind in which the air has cooled
+// below the 4 inch soil temperature, a sign of strong radiative cooling at
+// the surface. It is a heuristic, not a measurement of the vertical profile.
+func (data HourlyWeatherData) IsInversionLikely() bool {
+	if IsMissing(data.AirTemperature) || IsMissing(data.SoilTempFourInches) ||
+		IsMissing(data.WindSpeedAverage) || IsMissing(data.SolarRadiation) {
+		return false
+	}
+	return data.SolarRadiation <= inversionMaxSolarRadiation &&
+		data.WindSpeedAverage < inversionMaxWindSpeed &&
+		data.AirTemperature < data.SoilTempFourInches
+}
+
+// Lower and upper thresholds, in degrees Fahrenheit, for the crop heat unit
+// methods below.
+const (
+	cottonBaseF float32 = 55
+	cottonCapF  float32 = 86
+	cornBaseF   float32 = 50
+	cornCapF    float32 = 86
+)
+
+// heatUnits uses the modified average method: the daily maximum and minimum
+// are clamped to [baseF, capF] before averaging. Days without a valid
+// temperature contribute zero.
+func heatUnits(daily []DailyAggregate, baseF, capF float32) []float32 {
+	units := make([]float32, len(daily))
+	for i, day := range daily {
+		if IsMissing(day.MaxAirTemperature) || IsMissing(day.MinAirTemperature) {
+			continue
+		}
+		high := clamp(celsiusToFahrenheit(day.MaxAirTemperature), baseF, capF)
+		low := clamp(celsiusToFahrenheit(day.MinAirTemperature), baseF, capF)
+		units[i] = (high+low)/2 - baseF
+	}
+	return units
+}
+
+// sineHeatUnits uses the single sine method with a horizontal cutoff, as
+// AZMET does for its cotton heat units: the day's temperature is taken to
+// follow a sine curve between the minimum and maximum, and the area of that
+// curve between baseF and capF is integrated. Days without a valid
+// temperature contribute zero.
+func sineHeatUnits(daily []DailyAggregate, baseF, capF float32) []float32 {
+	units := make([]float32, len(daily))
+	for i, day := range daily {
+		if IsMissing(day.MaxAirTemperature) || IsMissing(day.MinAirTemperature) {
+			continue
+		}
+		units[i] = float32(singleSine(
+			float64(celsiusToFahrenheit(day.MaxAirTemperature)),
+			float64(celsiusToFahrenheit(day.MinAirTemperature)),
+			float64(baseF), float64(capF)))
+	}
+	return units
+}
+
+func singleSine(high, low, base, limit float64) float64 {
+	if high < low {
+		high, low = low, high
+	}
+	switch {
+	case high <= base:
+		return 0
+	case low >= limit:
+		return limit - base
+	case low >= base && high <= limit:
+		return (high+low)/2 - base
+	}
+
+	mean := (high + low) / 2
+	amplitude := (high - low) / 2
+	lower, upper := -math.Pi/2, math.Pi/2
+	if low < base {
+		lower = math.Asin((base - mean) / amplitude)
+	}
+	if high > limit {
+		upper = math.Asin((limit - mean) / amplitude)
+	}
+	return ((mean-base)*(upper-lower) +
+		amplitude*(math.Cos(lower)-math.Cos(upper)) +
+		(limit-base)*(math.Pi/2-upper)) / math.Pi
+}
+
+func clamp(val, low, high float32) float32 {
+	if val < low {
+		return low
+	}
+	if val > high {
+		return high
+	}
+	return val
+}
+
+func cumulative(vals []float32) []float32 {
+	sums := make([]float32, len(vals))
+	var total float32
+	for i, v := range vals {
+		total += v
+		sums[i] = total
+	}
+	return sums
+}
+
+// CottonHeatUnits returns daily cotton heat units using the 86/55 °F single
+// sine method with a horizontal cutoff, matching AZMET's published values.
+func CottonHeatUnits(daily []DailyAggregate) []float32 {
+	return sineHeatUnits(daily, cottonBaseF, cottonCapF)
+}
+
+// CornGDD returns daily corn growing degree days using the 86/50 °F method.
+func CornGDD(daily []DailyAggregate) []float32 {
+	return heatUnits(daily, cornBaseF, cornCapF)
+}
+
+// CumulativeCottonHeatUnits is the running season total of CottonHeatUnits.
+func CumulativeCottonHeatUnits(daily []DailyAggregate) []float32 {
+	return cumulative(CottonHeatUnits(daily))
+}
+
+// CumulativeCornGDD is the running season total of CornGDD.
+func CumulativeCornGDD(daily []DailyAggregate) []float32 {
+	return cumulative(CornGDD(daily))
+}
+
+// FrostRiskHours counts hours with AirTemperature below criticalF (degrees
+// Fahrenheit) and sums how far below it they fell, in °F-hours.
+func FrostRiskHours(data []HourlyWeatherData, criticalF float32) (hours int, degreeHours float32) {
+	for _, rec := range data {
+		if IsMissing(rec.AirTemperature) {
+			continue
+		}
+		tempF := celsiusToFahrenheit(rec.AirTemperature)
+		if tempF < criticalF {
+			hours++
+			degreeHours += criticalF - tempF
+		}
+	}
+	return hours, degreeHours
+}
+
+// GrowingDegreeDays returns daily growing degree days above baseF using the
+// simple average method, max((Tmax + Tmin) / 2 - base, 0) in °F. Days without
+// a valid temperature contribute zero.
+func GrowingDegreeDays(daily []DailyAggregate, baseF float32) []float32 {
+	gdd := make([]float32, len(daily))
+	for i, day := range daily {
+		if IsMissing(day.MaxAirTemperature) || IsMissing(day.MinAirTemperature) {
+			continue
+		}
+		mean := (celsiusToFahrenheit(day.MaxAirTemperature) + celsiusToFahrenheit(day.MinAirTemperature)) / 2
+		if mean > baseF {
+			gdd[i] = mean - baseF
+		}
+	}
+	return gdd
+}
+
+// DateReachingGDD accumulates GrowingDegreeDays from the first day in daily
+// and returns the date on which the running total first reaches targetGDD.
+// ok is false when the target is never reached.
+func DateReachingGDD(daily []DailyAggregate, baseF, targetGDD float32) (time.Time, bool) {
+	for i, total := range cumulative(GrowingDegreeDays(daily, baseF)) {
+		if total >= targetGDD {
+			return daily[i].Date, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeafWetnessHours(t *testing.T) {
+	humid := testRecord(t, 2020, 10, 1)
+	humid.RelativeHumidity = 95
+	dry := testRecord(t, 2020, 10, 2)
+	dry.RelativeHumidity = 30
+	rainy := testRecord(t, 2020, 11, 1)
+	rainy.RelativeHumidity = 60
+	rainy.Precipitation = 1.2
+	missingRH := testRecord(t, 2020, 11, 2)
+	missingRH.RelativeHumidity = MissingValue
+	missingPrecip := testRecord(t, 2020, 11, 3)
+	missingPrecip.RelativeHumidity = 40
+	missingPrecip.Precipitation = MissingValue
+
+	data := []HourlyWeatherData{humid, dry, rainy, missingRH, missingPrecip}
+
+	if got := LeafWetnessHours(data, DefaultLeafWetnessRH); got != 2 {
+		t.Errorf("LeafWetnessHours = %d, want 2", got)
+	}
+	byDay := LeafWetnessHoursByDay(data, DefaultLeafWetnessRH)
+	if byDay[10] != 1 || byDay[11] != 1 {
+		t.Errorf("LeafWetnessHoursByDay = %v, want day 10 and 11 at 1", byDay)
+	}
+}
+
+func TestIsInversionLikely(t *testing.T) {
+	calm := HourlyWeatherData{
+		AirTemperature:     5,
+		SoilTempFourInches: 12,
+		WindSpeedAverage:   0.5,
+		SolarRadiation:     0,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*HourlyWeatherData)
+		want   bool
+	}{
+		{"calm clear night", func(*HourlyWeatherData) {}, true},
+		{"daytime", func(d *HourlyWeatherData) { d.SolarRadiation = 1.5 }, false},
+		{"windy", func(d *HourlyWeatherData) { d.WindSpeedAverage = 4 }, false},
+		{"air warmer than soil", func(d *HourlyWeatherData) { d.AirTemperature = 15 }, false},
+		{"missing wind", func(d *HourlyWeatherData) { d.WindSpeedAverage = MissingValue }, false},
+	}
+	for _, tt := range tests {
+		rec := calm
+		tt.modify(&rec)
+		if got := rec.IsInversionLikely(); got != tt.want {
+			t.Errorf("%s: IsInversionLikely() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCropHeatUnits(t *testing.T) {
+	day := func(highF, lowF float32) DailyAggregate {
+		return DailyAggregate{
+			MaxAirTemperature: fahrenheitToCelsius(highF),
+			MinAirTemperature: fahrenheitToCelsius(lowF),
+		}
+	}
+	daily := []DailyAggregate{
+		day(80, 60),  // entirely between the thresholds
+		day(100, 90), // entirely above the cap
+		day(50, 40),  // entirely below the base
+		day(100, 70), // crosses the cap
+		day(70, 40),  // crosses the base
+		{MaxAirTemperature: MissingValue, MinAirTemperature: 10},
+	}
+
+	cotton := CottonHeatUnits(daily)
+	// Crossing values follow from the single sine integral: 100/70 gives
+	// ((85-55)(π/2+θ) + (86-55)(π/2-θ) - 15cosθ)/π with θ = asin(1/15), and
+	// 70/40 has its mean on the base, giving 15/π.
+	wantCotton := []float64{15, 31, 0, 25.71, 4.77, 0}
+	for i, want := range wantCotton {
+		if !approxEqual(float64(cotton[i]), want, 0.01) {
+			t.Errorf("cotton day %d = %v, want %v", i, cotton[i], want)
+		}
+	}
+
+	corn := CornGDD(daily)
+	// Modified average: both temperatures clamped to [50, 86].
+	wantCorn := []float64{20, 36, 0, 28, 10, 0}
+	for i, want := range wantCorn {
+		if !approxEqual(float64(corn[i]), want, 0.01) {
+			t.Errorf("corn day %d = %v, want %v", i, corn[i], want)
+		}
+	}
+
+	total := CumulativeCottonHeatUnits(daily)
+	if !approxEqual(float64(total[len(total)-1]), 15+31+25.71+4.77, 0.05) {
+		t.Errorf("season cotton total = %v", total[len(total)-1])
+	}
+}
+
+func TestFrostRiskHours(t *testing.T) {
+	// A night dipping through 32 °F and back.
+	tempsF := []float32{36, 33, 32, 30, 28, 31, 34}
+	var data []HourlyWeatherData
+	for i, f := range tempsF {
+		rec := testRecord(t, 2020, 15, i+1)
+		rec.AirTemperature = fahrenheitToCelsius(f)
+		data = append(data, rec)
+	}
+	missing := testRecord(t, 2020, 15, 8)
+	missing.AirTemperature = MissingValue
+	data = append(data, missing)
+
+	hours, degreeHours := FrostRiskHours(data, 32)
+	if hours != 3 {
+		t.Errorf("hours = %d, want 3", hours)
+	}
+	if !approxEqual(float64(degreeHours), 2+4+1, 0.01) {
+		t.Errorf("degree hours = %v, want 7", degreeHours)
+	}
+}
+
+func TestDateReachingGDD(t *testing.T) {
+	// Each day averages 70 °F, 20 GDD above a 50 °F base, except a cold day
+	// below the base and a day with no valid temperature.
+	var daily []DailyAggregate
+	start := time.Date(2020, time.April, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		day := DailyAggregate{
+			Date:              start.AddDate(0, 0, i),
+			MaxAirTemperature: fahrenheitToCelsius(85),
+			MinAirTemperature: fahrenheitToCelsius(55),
+		}
+		switch i {
+		case 1:
+			day.MaxAirTemperature, day.MinAirTemperature = fahrenheitToCelsius(50), fahrenheitToCelsius(30)
+		case 2:
+			day.MaxAirTemperature = MissingValue
+		}
+		daily = append(daily, day)
+	}
+
+	gdd := GrowingDegreeDays(daily, 50)
+	if !approxEqual(float64(gdd[0]), 20, 1e-3) || gdd[1] != 0 || gdd[2] != 0 {
+		t.Errorf("GrowingDegreeDays = %v", gdd[:3])
+	}
+
+	// 90 GDD needs a fifth warm day: April 1 and 4 through 7.
+	date, ok := DateReachingGDD(daily, 50, 90)
+	if want := start.AddDate(0, 0, 6); !ok || !date.Equal(want) {
+		t.Errorf("reached 90 GDD on %v (%v), want %v", date, ok, want)
+	}
+	if _, ok := DateReachingGDD(daily, 50, 1000); ok {
+		t.Errorf("1000 GDD reported as reached")
+	}
+}
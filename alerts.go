@@ -0,0 +1,64 @@
+package main
+
+import "time"
+
+type Comparator int
+
+const (
+	GreaterThan Comparator = iota
+	GreaterOrEqual
+	LessThan
+	LessOrEqual
+)
+
+func (c Comparator) compare(val, threshold float32) bool {
+	switch c {
+	case GreaterThan:
+		return val > threshold
+	case GreaterOrEqual:
+		return val >= threshold
+	case LessThan:
+		return val < threshold
+	case LessOrEqual:
+		return val <= threshold
+	default:
+		return false
+	}
+}
+
+// AlertRule triggers when Field compared against Threshold holds. Thresholds
+// are in the units AZMET reports, e.g. degrees Celsius and m/s.
+type AlertRule struct {
+	Field      func(HourlyWeatherData) float32
+	Comparator Comparator
+	Threshold  float32
+	Message    string
+}
+
+type Alert struct {
+	Rule    AlertRule
+	Time    time.Time
+	Value   float32
+	Message string
+}
+
+// EvaluateAlerts checks every record against every rule and returns the
+// alerts that fired, in record order. Missing values never trigger a rule.
+func EvaluateAlerts(data []HourlyWeatherData, rules []AlertRule) []Alert {
+	alerts := make([]Alert, 0)
+	for _, rec := range data {
+		for _, rule := range rules {
+			val := rule.Field(rec)
+			if IsMissing(val) || !rule.Comparator.compare(val, rule.Threshold) {
+				continue
+			}
+			alerts = append(alerts, Alert{
+				Rule:    rule,
+				Time:    rec.Time,
+				Value:   val,
+				Message: rule.Message,
+			})
+		}
+	}
+	return alerts
+}
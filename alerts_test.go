@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestEvaluateAlerts(t *testing.T) {
+	temp := func(rec HourlyWeatherData) float32 { return rec.AirTemperature }
+	wind := func(rec HourlyWeatherData) float32 { return rec.WindSpeedMax }
+	rules := []AlertRule{
+		{Field: temp, Comparator: GreaterThan, Threshold: 40, Message: "heat"},
+		{Field: temp, Comparator: LessOrEqual, Threshold: 0, Message: "freeze"},
+		{Field: wind, Comparator: GreaterOrEqual, Threshold: 15, Message: "wind"},
+		{Field: temp, Comparator: LessThan, Threshold: -10, Message: "hard freeze"},
+	}
+
+	readings := []struct{ temp, wind float32 }{
+		{40, 15},           // heat stops short, wind fires at the threshold
+		{41, 3},            // heat
+		{0, MissingValue},  // freeze at the threshold, missing wind ignored
+		{MissingValue, 20}, // missing temperature ignored, wind fires
+		{-12, 1},           // freeze and hard freeze
+	}
+	var data []HourlyWeatherData
+	for i, r := range readings {
+		rec := testRecord(t, 2020, 20, i+1)
+		rec.AirTemperature, rec.WindSpeedMax = r.temp, r.wind
+		data = append(data, rec)
+	}
+
+	alerts := EvaluateAlerts(data, rules)
+	want := []struct {
+		record  int
+		message string
+	}{
+		{0, "wind"},
+		{1, "heat"},
+		{2, "freeze"},
+		{3, "wind"},
+		{4, "freeze"},
+		{4, "hard freeze"},
+	}
+	if len(alerts) != len(want) {
+		t.Fatalf("got %d alerts, want %d: %v", len(alerts), len(want), alerts)
+	}
+	for i, w := range want {
+		a := alerts[i]
+		if a.Message != w.message || !a.Time.Equal(data[w.record].Time) {
+			t.Errorf("alert %d = %q at %v, want %q at %v", i, a.Message, a.Time, w.message, data[w.record].Time)
+		}
+	}
+	if alerts[1].Value != 41 {
+		t.Errorf("heat alert Value = %v, want 41", alerts[1].Value)
+	}
+}
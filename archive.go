@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// runArchive implements `azmet archive -s <station> -dir <path>`, mirroring
+// every published year for a station into dir under AZMET's own filenames.
+// Past years already present in dir are skipped; the current year is always
+// fetched again since AZMET keeps appending to it.
+func runArchive(client *Client, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("archive", flag.ContinueOnError)
+	station := flags.Int("s", int(PhoenixGreenway), "the weather station to archive")
+	dir := flags.String("dir", ".", "the directory to write raw data files to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+
+	fetched := make([]int, 0)
+	current := client.now().Year()
+	last := current
+	if last > client.MaxYear {
+		last = client.MaxYear
+	}
+	for year := client.MinYear; year <= last; year++ {
+		path := filepath.Join(*dir, generateFilename(WeatherStation(*station), year))
+		if _, err := os.Stat(path); err == nil && year != current {
+			fmt.Fprintf(out, "%d: already archived\n", year)
+			continue
+		}
+
+		raw, err := client.DownloadRawData(WeatherStation(*station), year)
+		if errors.Is(err, ErrNotFound) {
+			fmt.Fprintf(out, "%d: not published\n", year)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("archiving %d: %w", year, err)
+		}
+
+		if err := os.WriteFile(path, raw, 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%d: fetched %d bytes\n", year, len(raw))
+		fetched = append(fetched, year)
+	}
+
+	fmt.Fprintf(out, "fetched %d years: %v\n", len(fetched), fetched)
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunArchive(t *testing.T) {
+	files := map[string]string{
+		"1219rh.txt": hourlyCSV(2019, 1),
+		"1220rh.txt": hourlyCSV(2020, 1),
+		"1221rh.txt": hourlyCSV(2021, 2),
+	}
+	client, transport := newStubClient(time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC), files)
+	client.MinYear = 2018
+	dir := t.TempDir()
+
+	// A stale copy of the current year and an archived past year.
+	stale := filepath.Join(dir, "1221rh.txt")
+	if err := os.WriteFile(stale, []byte(hourlyCSV(2021, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "1219rh.txt"), []byte("kept"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runArchive(client, []string{"-s", "12", "-dir", dir}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"2018: not published", "2019: already archived", "fetched 2 years: [2020 2021]"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+	if n := transport.requested("1219rh.txt"); n != 0 {
+		t.Errorf("archived past year was fetched %d times", n)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "1219rh.txt")); string(got) != "kept" {
+		t.Errorf("archived past year was overwritten")
+	}
+	if got, _ := os.ReadFile(stale); string(got) != files["1221rh.txt"] {
+		t.Errorf("current year was not refreshed")
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "1220rh.txt")); string(got) != files["1220rh.txt"] {
+		t.Errorf("2020 was not archived")
+	}
+}
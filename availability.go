@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+type YearStatus struct {
+	Available bool
+	Records   int
+	// Coverage is the fraction of numeric observations that are not missing.
+	Coverage float64
+	Err      error
+}
+
+// availabilityWorkers bounds how many years AvailabilitySummary fetches at
+// once, so a long range of years does not flood the AZMET server.
+const availabilityWorkers = 4
+
+// AvailabilitySummary fetches each year for station and reports whether its
+// file exists, how many records it holds and how complete they are. A year
+// that fails for any reason other than ErrNotFound carries the error in Err.
+// Years are fetched concurrently by a small pool of workers, through the
+// Client's cache when one is set.
+func (c *Client) AvailabilitySummary(station WeatherStation, years []int) map[int]YearStatus {
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	summary := make(map[int]YearStatus)
+
+	for i := 0; i < availabilityWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for year := range jobs {
+				status := c.yearStatus(station, year)
+				mu.Lock()
+				summary[year] = status
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, year := range years {
+		jobs <- year
+	}
+	close(jobs)
+	wg.Wait()
+
+	return summary
+}
+
+func (c *Client) yearStatus(station WeatherStation, year int) YearStatus {
+	data, err := c.DownloadHourlyData(station, year)
+	if err != nil {
+		status := YearStatus{}
+		if !errors.Is(err, ErrNotFound) {
+			status.Err = err
+		}
+		return status
+	}
+	return YearStatus{
+		Available: true,
+		Records:   len(data),
+		Coverage:  fieldCoverage(data),
+	}
+}
+
+func AvailabilitySummary(station WeatherStation, years []int) map[int]YearStatus {
+	return NewClient().AvailabilitySummary(station, years)
+}
+
+func fieldCoverage(data []HourlyWeatherData) float64 {
+	total, present := 0, 0
+	for _, rec := range data {
+		s := reflect.ValueOf(rec)
+		for i := 0; i < s.NumField(); i++ {
+			field := s.Field(i)
+			if field.Kind() != reflect.Float32 {
+				continue
+			}
+			total++
+			if !IsMissing(float32(field.Float())) {
+				present++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(present) / float64(total)
+}
+
+// HoursPerDay counts how many distinct hours each day of year has a record
+// for. Data spanning several years is folded onto the same day numbers.
+func HoursPerDay(data []HourlyWeatherData) map[int]int {
+	seen := make(map[[2]int]bool)
+	counts := make(map[int]int)
+	for _, rec := range data {
+		key := [2]int{rec.Day, rec.Hour}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		counts[rec.Day]++
+	}
+	return counts
+}
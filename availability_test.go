@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAvailabilitySummary(t *testing.T) {
+	files := map[string]string{
+		"1218rh.txt": hourlyCSV(2018, 2),
+		"1220rh.txt": hourlyCSV(2020, 1) + "2020,2,1,999,30,1,0,0,15,15,1,1,180,10,2,0.1,1,5\n",
+	}
+	client, transport := newStubClient(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC), files)
+	client.Cache = NewMemoryCache(10)
+
+	years := []int{2017, 2018, 2019, 2020}
+	summary := client.AvailabilitySummary(PhoenixGreenway, years)
+	if len(summary) != len(years) {
+		t.Fatalf("got %d years, want %d", len(summary), len(years))
+	}
+
+	for _, year := range []int{2017, 2019} {
+		if status := summary[year]; status.Available || status.Err != nil {
+			t.Errorf("%d: want unavailable without error, got %+v", year, status)
+		}
+	}
+	if status := summary[2018]; !status.Available || status.Records != 48 || status.Coverage != 1 {
+		t.Errorf("2018: got %+v, want 48 fully covered records", status)
+	}
+	status := summary[2020]
+	if !status.Available || status.Records != 25 {
+		t.Errorf("2020: got %+v, want 25 records", status)
+	}
+	if want := 1 - 1.0/(25*15); !approxEqual(status.Coverage, want, 1e-9) {
+		t.Errorf("2020: coverage = %v, want %v", status.Coverage, want)
+	}
+
+	client.AvailabilitySummary(PhoenixGreenway, []int{2018})
+	if n := transport.requested("1218rh.txt"); n != 1 {
+		t.Errorf("2018 fetched %d times, want 1 with the cache in place", n)
+	}
+}
+
+func TestHoursPerDay(t *testing.T) {
+	var data []HourlyWeatherData
+	for hour := 1; hour <= 24; hour++ {
+		data = append(data, testRecord(t, 2020, 10, hour))
+	}
+	for _, hour := range []int{1, 2, 3, 7, 7, 24} { // a short day with a duplicate
+		data = append(data, testRecord(t, 2020, 11, hour))
+	}
+
+	counts := HoursPerDay(data)
+	if counts[10] != 24 || counts[11] != 5 || len(counts) != 2 {
+		t.Errorf("HoursPerDay = %v, want 24 for day 10 and 5 for day 11", counts)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"container/list"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cache stores raw AZMET data files keyed by station and year. Get reports
+// ok as false, with a nil error, when nothing is stored for the key; an error
+// means the backend itself failed. Set replaces any stored value and Delete
+// of an absent key is not an error. Implementations must be safe for
+// concurrent use and must not retain or modify the slices they are given or
+// return.
+type Cache interface {
+	Get(station WeatherStation, year int) (raw []byte, ok bool, err error)
+	Set(station WeatherStation, year int, raw []byte) error
+	Delete(station WeatherStation, year int) error
+}
+
+type cacheKey struct {
+	Station WeatherStation
+	Year    int
+}
+
+type memoryEntry struct {
+	key cacheKey
+	raw []byte
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used file
+// once it holds more than its capacity.
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[cacheKey]*list.Element
+}
+
+// NewMemoryCache returns an empty MemoryCache holding at most capacity files.
+// A capacity below 1 is treated as 1.
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[cacheKey]*list.Element),
+	}
+}
+
+func (m *MemoryCache) Get(station WeatherStation, year int) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	elem, ok := m.entries[cacheKey{station, year}]
+	if !ok {
+		return nil, false, nil
+	}
+	m.order.MoveToFront(elem)
+	return append([]byte(nil), elem.Value.(*memoryEntry).raw...), true, nil
+}
+
+func (m *MemoryCache) Set(station WeatherStation, year int, raw []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := cacheKey{station, year}
+	raw = append([]byte(nil), raw...)
+	if elem, ok := m.entries[key]; ok {
+		elem.Value.(*memoryEntry).raw = raw
+		m.order.MoveToFront(elem)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(&memoryEntry{key, raw})
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+func (m *MemoryCache) Delete(station WeatherStation, year int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := cacheKey{station, year}
+	if elem, ok := m.entries[key]; ok {
+		m.order.Remove(elem)
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// DirCache is a Cache that keeps files in a directory under AZMET's own
+// filenames, the same layout the archive command writes.
+type DirCache struct {
+	Dir string
+}
+
+func NewDirCache(dir string) *DirCache {
+	return &DirCache{Dir: dir}
+}
+
+func (d *DirCache) path(station WeatherStation, year int) string {
+	return filepath.Join(d.Dir, generateFilename(station, year))
+}
+
+func (d *DirCache) Get(station WeatherStation, year int) ([]byte, bool, error) {
+	raw, err := os.ReadFile(d.path(station, year))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return raw, true, nil
+}
+
+// Set writes through a temporary file so a concurrent Get never sees a
+// partially written file. Files are readable by all, as the archive command
+// leaves them.
+func (d *DirCache) Set(station WeatherStation, year int, raw []byte) error {
+	if err := os.MkdirAll(d.Dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(d.Dir, ".azmet-*")
+	if err != nil {
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(station, year))
+}
+
+func (d *DirCache) Delete(station WeatherStation, year int) error {
+	err := os.Remove(d.path(station, year))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
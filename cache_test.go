@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingCache wraps a MemoryCache and logs every call made to it.
+type recordingCache struct {
+	mu    sync.Mutex
+	inner *MemoryCache
+	calls []string
+}
+
+func (r *recordingCache) log(op string, station WeatherStation, year int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, fmt.Sprintf("%s %d/%d", op, station, year))
+}
+
+func (r *recordingCache) Get(station WeatherStation, year int) ([]byte, bool, error) {
+	r.log("get", station, year)
+	return r.inner.Get(station, year)
+}
+
+func (r *recordingCache) Set(station WeatherStation, year int, raw []byte) error {
+	r.log("set", station, year)
+	return r.inner.Set(station, year, raw)
+}
+
+func (r *recordingCache) Delete(station WeatherStation, year int) error {
+	r.log("delete", station, year)
+	return r.inner.Delete(station, year)
+}
+
+func (r *recordingCache) take() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := strings.Join(r.calls, ", ")
+	r.calls = nil
+	return calls
+}
+
+func TestClientCacheCalls(t *testing.T) {
+	files := map[string]string{
+		"1220rh.txt": hourlyCSV(2020, 1),
+		"1219rh.txt": "not,a,valid,file\n",
+		"1221rh.txt": hourlyCSV(2021, 1),
+	}
+	client, transport := newStubClient(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC), files)
+	cache := &recordingCache{inner: NewMemoryCache(10)}
+	client.Cache = cache
+
+	steps := []struct {
+		year  int
+		calls string
+	}{
+		{2020, "get 12/2020, set 12/2020"}, // miss, then stored
+		{2020, "get 12/2020"},              // hit
+		{2021, ""},                         // current year bypasses the cache
+		{2019, "get 12/2019, set 12/2019, delete 12/2019"}, // unparseable, evicted
+	}
+	for _, step := range steps {
+		client.DownloadHourlyData(PhoenixGreenway, step.year)
+		if got := cache.take(); got != step.calls {
+			t.Errorf("%d: cache calls %q, want %q", step.year, got, step.calls)
+		}
+	}
+	if n := transport.requested("1220rh.txt"); n != 1 {
+		t.Errorf("cached year fetched %d times, want 1", n)
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set(Tucson, 2018, []byte("a"))
+	cache.Set(Tucson, 2019, []byte("b"))
+	cache.Get(Tucson, 2018) // 2019 is now least recently used
+	cache.Set(Tucson, 2020, []byte("c"))
+
+	if _, ok, _ := cache.Get(Tucson, 2019); ok {
+		t.Errorf("least recently used entry was not evicted")
+	}
+	for _, year := range []int{2018, 2020} {
+		if _, ok, _ := cache.Get(Tucson, year); !ok {
+			t.Errorf("%d was evicted", year)
+		}
+	}
+
+	tiny := NewMemoryCache(0)
+	tiny.Set(Tucson, 2018, []byte("a"))
+	if raw, ok, _ := tiny.Get(Tucson, 2018); !ok || string(raw) != "a" {
+		t.Errorf("capacity 0 cache did not hold one entry")
+	}
+}
+
+func TestDirCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "cache")
+	cache := NewDirCache(dir)
+
+	if _, ok, err := cache.Get(Tucson, 2020); ok || err != nil {
+		t.Fatalf("Get before the directory exists = %v, %v, want a miss", ok, err)
+	}
+	if err := cache.Delete(Tucson, 2020); err != nil {
+		t.Errorf("Delete of a missing key: %v", err)
+	}
+
+	if err := cache.Set(Tucson, 2020, []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	raw, ok, err := cache.Get(Tucson, 2020)
+	if err != nil || !ok || string(raw) != "first" {
+		t.Fatalf("Get after Set = %q, %v, %v", raw, ok, err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "120rh.txt"))
+	if err != nil {
+		t.Fatalf("cached file not under the archive filename: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0644 {
+		t.Errorf("cached file mode = %v, want 0644 like the archive command", mode)
+	}
+
+	if err := cache.Set(Tucson, 2020, []byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _, _ := cache.Get(Tucson, 2020); string(raw) != "second" {
+		t.Errorf("Get after overwrite = %q, want \"second\"", raw)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("cache directory holds %d files, want only the cached year", len(entries))
+	}
+
+	if err := cache.Delete(Tucson, 2020); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := cache.Get(Tucson, 2020); ok || err != nil {
+		t.Errorf("Get after Delete = %v, %v, want a miss", ok, err)
+	}
+	if err := cache.Delete(Tucson, 2020); err != nil {
+		t.Errorf("second Delete: %v", err)
+	}
+}
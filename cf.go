@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// CFVariable describes a field using Climate and Forecast (CF) metadata
+// conventions. Name is a unique variable name suitable for a column header;
+// StandardName is empty where the CF standard name table has no match.
+type CFVariable struct {
+	Name         string
+	StandardName string
+	LongName     string
+	Units        string
+}
+
+// CFMetadata maps each numeric HourlyWeatherData field name to its CF
+// description. Units are those AZMET reports in.
+var CFMetadata = map[string]CFVariable{
+	"AirTemperature":       {"air_temperature", "air_temperature", "air temperature", "degC"},
+	"RelativeHumidity":     {"relative_humidity", "relative_humidity", "relative humidity", "percent"},
+	"VaporPressureDeficit": {"vapor_pressure_deficit", "water_vapor_saturation_deficit_in_air", "vapor pressure deficit", "kPa"},
+	"SolarRadiation":       {"solar_radiation", "integral_wrt_time_of_surface_downwelling_shortwave_flux_in_air", "total solar radiation over the hour", "MJ m-2"},
+	"Precipitation":        {"precipitation", "lwe_thickness_of_precipitation_amount", "precipitation over the hour", "mm"},
+	"SoilTempFourInches":   {"soil_temperature_4in", "soil_temperature", "soil temperature at 4 inches depth", "degC"},
+	"SoilTempTwentyInches": {"soil_temperature_20in", "soil_temperature", "soil temperature at 20 inches depth", "degC"},
+	"WindSpeedAverage":     {"wind_speed", "wind_speed", "scalar mean wind speed", "m s-1"},
+	"WindMagnitudeVector":  {"wind_speed_vector_mean", "wind_speed", "vector mean wind speed", "m s-1"},
+	"WindDirectionVector":  {"wind_from_direction", "wind_from_direction", "vector mean wind direction", "degree"},
+	"WindDirectionStdDev":  {"wind_from_direction_stddev", "", "standard deviation of wind direction", "degree"},
+	"WindSpeedMax":         {"wind_speed_of_gust", "wind_speed_of_gust", "maximum wind speed over the hour", "m s-1"},
+	"Evapotranspiration":   {"reference_evapotranspiration", "", "reference evapotranspiration over the hour", "mm"},
+	"VaporPressureActual":  {"vapor_pressure", "water_vapor_partial_pressure_in_air", "actual vapor pressure", "kPa"},
+	"DewpointHourAverage":  {"dew_point_temperature", "dew_point_temperature", "mean dew point temperature", "degC"},
+}
+
+// cfFields returns the indexes of HourlyWeatherData's CF-described fields in
+// declaration order.
+func cfFields() []int {
+	t := reflect.TypeOf(HourlyWeatherData{})
+	fields := make([]int, 0, len(CFMetadata))
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := CFMetadata[t.Field(i).Name]; ok {
+			fields = append(fields, i)
+		}
+	}
+	return fields
+}
+
+// WriteCF writes records as "csv" or "json" with a "time" column in RFC 3339
+// followed by one column per field named after its CFVariable Name. Missing
+// values are written as empty CSV fields and JSON nulls.
+func WriteCF(w io.Writer, data []HourlyWeatherData, format string) error {
+	t := reflect.TypeOf(HourlyWeatherData{})
+	fields := cfFields()
+
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		header := []string{"time"}
+		for _, i := range fields {
+			header = append(header, CFMetadata[t.Field(i).Name].Name)
+		}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, rec := range data {
+			v := reflect.ValueOf(rec)
+			row := []string{rec.Time.Format(time.RFC3339)}
+			for _, i := range fields {
+				val := float32(v.Field(i).Float())
+				if IsMissing(val) {
+					row = append(row, "")
+					continue
+				}
+				row = append(row, strconv.FormatFloat(float64(val), 'f', -1, 32))
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		rows := make([]map[string]interface{}, 0, len(data))
+		for _, rec := range data {
+			v := reflect.ValueOf(rec)
+			row := map[string]interface{}{"time": rec.Time.Format(time.RFC3339)}
+			for _, i := range fields {
+				name := CFMetadata[t.Field(i).Name].Name
+				val := float32(v.Field(i).Float())
+				if IsMissing(val) {
+					row[name] = nil
+					continue
+				}
+				row[name] = val
+			}
+			rows = append(rows, row)
+		}
+		return json.NewEncoder(w).Encode(rows)
+	default:
+		return fmt.Errorf("unsupported CF export format: %s", format)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCFMetadataCoversNumericFields(t *testing.T) {
+	typ := reflect.TypeOf(HourlyWeatherData{})
+	names := make(map[string]bool)
+	numeric := 0
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type.Kind() != reflect.Float32 {
+			continue
+		}
+		numeric++
+		cf, ok := CFMetadata[field.Name]
+		if !ok {
+			t.Errorf("%s has no CF metadata", field.Name)
+			continue
+		}
+		if cf.Name == "" || cf.LongName == "" || cf.Units == "" {
+			t.Errorf("%s has incomplete CF metadata: %+v", field.Name, cf)
+		}
+		if names[cf.Name] {
+			t.Errorf("%s reuses variable name %q", field.Name, cf.Name)
+		}
+		names[cf.Name] = true
+	}
+	if len(CFMetadata) != numeric {
+		t.Errorf("CFMetadata has %d entries for %d numeric fields", len(CFMetadata), numeric)
+	}
+}
+
+func TestWriteCFMissingValues(t *testing.T) {
+	rec := testRecord(t, 2020, 1, 1)
+	rec.AirTemperature = 12.5
+	rec.RelativeHumidity = MissingValue
+
+	var buf bytes.Buffer
+	if err := WriteCF(&buf, []HourlyWeatherData{rec}, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.HasPrefix(lines[0], "time,air_temperature,relative_humidity,") {
+		t.Errorf("header = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "2020-01-01T01:00:00-07:00,12.5,,") {
+		t.Errorf("row = %q", lines[1])
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrNotFound is returned when AZMET has no data file for the requested
+// station and year.
+var ErrNotFound = errors.New("weather data not found")
+
+// ErrIncomplete is returned when a download holds fewer records than the
+// Client's MinCompleteness allows.
+var ErrIncomplete = errors.New("weather data incomplete")
+
+// Client fetches AZMET data. The zero value is not usable; construct one with
+// NewClient and override fields as needed.
+type Client struct {
+	// HTTPClient performs every request. Replace it, or its Transport, to
+	// tune connection pooling; see BulkTransport for settings suited to
+	// fetching many files.
+	HTTPClient *http.Client
+	// Now reports the current time and is used by every time-dependent code
+	// path. Tests can replace it with a fixed clock.
+	Now func() time.Time
+	// FallbackToPreviousYear makes a request for the current year that is not
+	// yet published fall back to the previous year's file. The substitution is
+	// logged, and the Year of the returned records reflects the year served.
+	FallbackToPreviousYear bool
+	// MinYear and MaxYear bound the years DownloadHourlyData accepts. They
+	// default to AZMET's published range, 2003 through 2099.
+	MinYear int
+	MaxYear int
+	// MinCompleteness, when positive, is the fraction of the expected hourly
+	// records a download must contain to be accepted. A past year is expected
+	// to have a record for every hour; the current year one for every hour of
+	// the days completed so far. Downloads that fall short fail with
+	// ErrIncomplete, which usually signals a truncated transfer.
+	MinCompleteness float64
+	// ParseOptions controls how downloaded files are parsed.
+	ParseOptions ParseOptions
+	// Headers are added to every request. They take precedence over the
+	// defaults net/http would otherwise send, such as User-Agent.
+	Headers http.Header
+	// MaxRetries is how many times a request that failed with a retryable
+	// status is repeated. Zero disables retries.
+	MaxRetries int
+	// RetryStatusCodes lists the retryable statuses. When nil every 5xx
+	// status is retried.
+	RetryStatusCodes []int
+	// RetryDelay is the wait before the first retry, doubling for each one
+	// after. A Retry-After header on a 429 or 503 response takes precedence.
+	RetryDelay time.Duration
+	// MaxRetryDelay caps any single wait between retries, including one
+	// requested by a Retry-After header, so a misbehaving server cannot stall
+	// the caller. NewClient sets it to one minute; zero disables the cap.
+	MaxRetryDelay time.Duration
+	// Cache, when set, stores raw files for years before the current one,
+	// which AZMET no longer changes. The current year is always fetched.
+	Cache Cache
+}
+
+const (
+	defaultMinYear       = 2003
+	defaultMaxYear       = 2099
+	defaultMaxRetryDelay = time.Minute
+)
+
+func NewClient() *Client {
+	return &Client{
+		HTTPClient: &http.Client{
+			Timeout: time.Second * 10,
+		},
+		Now:           time.Now,
+		MinYear:       defaultMinYear,
+		MaxYear:       defaultMaxYear,
+		MaxRetryDelay: defaultMaxRetryDelay,
+	}
+}
+
+// BulkTransport returns a transport tuned for fetching many station files
+// from the AZMET host: keep-alives stay enabled and the per-host idle pool
+// is raised from net/http's default of 2 so that concurrent downloads reuse
+// connections instead of repeatedly dialing.
+//
+//	client := NewClient()
+//	client.HTTPClient.Transport = BulkTransport()
+func BulkTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 16
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.DisableKeepAlives = false
+	return transport
+}
+
+func (c *Client) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+func (c *Client) DownloadHourlyData(station WeatherStation, year int) ([]HourlyWeatherData, error) {
+	data, err := c.downloadHourlyData(station, year)
+	if errors.Is(err, ErrNotFound) && c.FallbackToPreviousYear && year == c.now().Year() {
+		log.Printf("station %d has no data for %d yet, serving %d instead", station, year, year-1)
+		return c.downloadHourlyData(station, year-1)
+	}
+	return data, err
+}
+
+// RefreshHourlyData downloads year for station and merges it into cached with
+// MergeHourlyData, so a stored copy of a growing year can be brought up to
+// date without duplicating the hours it already holds. Fresh records replace
+// cached ones with the same Time. On error cached is returned unchanged.
+func (c *Client) RefreshHourlyData(station WeatherStation, year int, cached []HourlyWeatherData) ([]HourlyWeatherData, error) {
+	fresh, err := c.DownloadHourlyData(station, year)
+	if err != nil {
+		return cached, err
+	}
+	return MergeHourlyData(cached, fresh), nil
+}
+
+// fetch requests the raw data file for station and year. The caller must
+// close the returned body.
+func (c *Client) fetch(station WeatherStation, year int) (io.ReadCloser, error) {
+
+	if year < c.MinYear || year > c.MaxYear {
+		return nil, fmt.Errorf("invalid year to fetch Phoenix weather data: %d", year)
+	}
+
+	url := generateUrl(station, year)
+
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.Headers {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
+	var response *http.Response
+	delay := c.RetryDelay
+	for attempt := 0; ; attempt++ {
+		response, err = c.HTTPClient.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		if attempt >= c.MaxRetries || !c.retryable(response.StatusCode) {
+			break
+		}
+		wait := delay
+		if after, ok := retryAfter(response, c.now()); ok {
+			wait = after
+		}
+		if c.MaxRetryDelay > 0 && wait > c.MaxRetryDelay {
+			wait = c.MaxRetryDelay
+		}
+		response.Body.Close()
+		time.Sleep(wait)
+		delay *= 2
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		if response.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("station %d year %d: %w", station, year, ErrNotFound)
+		}
+		return nil, fmt.Errorf("unexpected status fetching %s: %s", url, response.Status)
+	}
+
+	return response.Body, nil
+}
+
+func (c *Client) retryable(status int) bool {
+	if c.RetryStatusCodes == nil {
+		return status >= 500 && status <= 599
+	}
+	for _, code := range c.RetryStatusCodes {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter reads the Retry-After header of a 429 or 503 response, given
+// either as a number of seconds or an HTTP date.
+func retryAfter(response *http.Response, now time.Time) (time.Duration, bool) {
+	if response.StatusCode != http.StatusTooManyRequests && response.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// DownloadRawData returns the unparsed data file for station and year, for
+// callers that want to archive the bytes and parse them, possibly several
+// times, with ParseHourlyData.
+func (c *Client) DownloadRawData(station WeatherStation, year int) ([]byte, error) {
+	cacheable := c.Cache != nil && year < c.now().Year()
+	if cacheable {
+		raw, ok, err := c.Cache.Get(station, year)
+		if err != nil {
+			return []byte{}, err
+		}
+		if ok {
+			return raw, nil
+		}
+	}
+
+	body, err := c.fetch(station, year)
+	if err != nil {
+		return []byte{}, err
+	}
+	defer body.Close()
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if cacheable {
+		if err := c.Cache.Set(station, year, raw); err != nil {
+			return []byte{}, err
+		}
+	}
+	return raw, nil
+}
+
+func (c *Client) downloadHourlyData(station WeatherStation, year int) ([]HourlyWeatherData, error) {
+	raw, err := c.DownloadRawData(station, year)
+	if err != nil {
+		return []HourlyWeatherData{}, err
+	}
+
+	data, err := ReadHourlyDataWithOptions(io.NopCloser(bytes.NewReader(raw)), c.ParseOptions)
+	if err != nil {
+		if c.Cache != nil {
+			c.Cache.Delete(station, year)
+		}
+		return []HourlyWeatherData{}, err
+	}
+
+	if c.MinCompleteness > 0 {
+		expected := c.expectedRecords(year)
+		if float64(len(data)) < c.MinCompleteness*float64(expected) {
+			return []HourlyWeatherData{}, fmt.Errorf("station %d year %d: %w: %d of %d expected records", station, year, ErrIncomplete, len(data), expected)
+		}
+	}
+
+	return data, nil
+}
+
+func (c *Client) expectedRecords(year int) int {
+	now := c.now()
+	if year == now.Year() {
+		return (now.YearDay() - 1) * 24
+	}
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() * 24
+}
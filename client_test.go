@@ -0,0 +1,420 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubTransport serves AZMET files from memory, keyed by filename. Unknown
+// files get a 404.
+type stubTransport struct {
+	mu       sync.Mutex
+	files    map[string]string
+	requests []*http.Request
+}
+
+func (s *stubTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+	body, ok := s.files[path.Base(r.URL.Path)]
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+func (s *stubTransport) requested(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.requests {
+		if path.Base(r.URL.Path) == name {
+			n++
+		}
+	}
+	return n
+}
+
+// hourlyCSV renders days full days of plausible hourly records for year.
+func hourlyCSV(year, days int) string {
+	var b strings.Builder
+	for day := 1; day <= days; day++ {
+		for hour := 1; hour <= 24; hour++ {
+			fmt.Fprintf(&b, "%d,%d,%d,20,30,1,0,0,15,15,1,1,180,10,2,0.1,1,5\n", year, day, hour)
+		}
+	}
+	return b.String()
+}
+
+func newStubClient(now time.Time, files map[string]string) (*Client, *stubTransport) {
+	transport := &stubTransport{files: files}
+	client := NewClient()
+	client.HTTPClient.Transport = transport
+	client.Now = func() time.Time { return now }
+	return client, transport
+}
+
+func TestClientClockExpectedRecords(t *testing.T) {
+	client, _ := newStubClient(time.Date(2021, time.January, 11, 8, 0, 0, 0, time.UTC), nil)
+
+	if got := client.expectedRecords(2021); got != 10*24 {
+		t.Errorf("expectedRecords(current year) = %d, want %d", got, 10*24)
+	}
+	if got := client.expectedRecords(2020); got != 366*24 {
+		t.Errorf("expectedRecords(leap year) = %d, want %d", got, 366*24)
+	}
+	if got := client.expectedRecords(2019); got != 365*24 {
+		t.Errorf("expectedRecords(common year) = %d, want %d", got, 365*24)
+	}
+}
+
+func TestClientClockFallbackYear(t *testing.T) {
+	files := map[string]string{"1220rh.txt": hourlyCSV(2020, 1)}
+
+	client, _ := newStubClient(time.Date(2021, time.January, 2, 0, 0, 0, 0, time.UTC), files)
+	client.FallbackToPreviousYear = true
+	data, err := client.DownloadHourlyData(PhoenixGreenway, 2021)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 || data[0].Year != 2020 {
+		t.Errorf("expected fallback to 2020 while the clock reads 2021")
+	}
+
+	client.Now = func() time.Time { return time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC) }
+	if _, err := client.DownloadHourlyData(PhoenixGreenway, 2021); err == nil {
+		t.Errorf("expected no fallback for a past year once the clock reads 2022")
+	}
+}
+
+func TestClientClockCacheEligibility(t *testing.T) {
+	files := map[string]string{
+		"1220rh.txt": hourlyCSV(2020, 1),
+		"1221rh.txt": hourlyCSV(2021, 1),
+	}
+	client, _ := newStubClient(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC), files)
+	cache := NewMemoryCache(10)
+	client.Cache = cache
+
+	for _, year := range []int{2020, 2021} {
+		if _, err := client.DownloadHourlyData(PhoenixGreenway, year); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok, _ := cache.Get(PhoenixGreenway, 2020); !ok {
+		t.Errorf("expected the completed year to be cached")
+	}
+	if _, ok, _ := cache.Get(PhoenixGreenway, 2021); ok {
+		t.Errorf("expected the current year not to be cached")
+	}
+}
+
+// newServerClient returns a Client whose requests for the AZMET host are
+// dialed to a local test server running handler instead.
+func newServerClient(t *testing.T, now time.Time, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	transport := BulkTransport()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	client := NewClient()
+	client.HTTPClient.Transport = transport
+	client.Now = func() time.Time { return now }
+	return client
+}
+
+func TestClientFallbackUnpublishedYear(t *testing.T) {
+	var mu sync.Mutex
+	var served []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		served = append(served, path.Base(r.URL.Path))
+		mu.Unlock()
+		if path.Base(r.URL.Path) != "124rh.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, hourlyCSV(2024, 2))
+	})
+	now := time.Date(2025, time.January, 1, 6, 0, 0, 0, time.UTC)
+
+	client := newServerClient(t, now, handler)
+	if _, err := client.DownloadHourlyData(Tucson, 2025); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("without fallback: err = %v, want ErrNotFound", err)
+	}
+
+	client.FallbackToPreviousYear = true
+	data, err := client.DownloadHourlyData(Tucson, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 48 || data[0].Year != 2024 {
+		t.Errorf("got %d records for year %d, want 48 for 2024", len(data), data[0].Year)
+	}
+	want := []string{"125rh.txt", "125rh.txt", "124rh.txt"}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(served, " ") != strings.Join(want, " ") {
+		t.Errorf("requested %v, want %v", served, want)
+	}
+}
+
+func TestBulkTransportReusesConnections(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, hourlyCSV(2020, 1))
+	})
+	client := newServerClient(t, time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), handler)
+
+	transport := client.HTTPClient.Transport.(*http.Transport)
+	dial := transport.DialContext
+	var dials int32
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return dial(ctx, network, addr)
+	}
+
+	for year := 2015; year <= 2020; year++ {
+		if _, err := client.DownloadHourlyData(PhoenixGreenway, year); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&dials); n != 1 {
+		t.Errorf("dialed %d connections for six requests, want 1", n)
+	}
+}
+
+func TestClientYearRange(t *testing.T) {
+	files := map[string]string{"1298rh.txt": hourlyCSV(1998, 1)}
+	client, transport := newStubClient(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC), files)
+
+	if _, err := client.DownloadHourlyData(PhoenixGreenway, 1998); err == nil {
+		t.Errorf("expected 1998 to be rejected by the default range")
+	}
+	if n := transport.requested("1298rh.txt"); n != 0 {
+		t.Errorf("out of range year was requested %d times", n)
+	}
+
+	client.MinYear = 1987
+	if _, err := client.DownloadHourlyData(PhoenixGreenway, 1998); err != nil {
+		t.Errorf("custom range: %v", err)
+	}
+	client.MaxYear = 2019
+	if _, err := client.DownloadHourlyData(PhoenixGreenway, 2020); err == nil {
+		t.Errorf("expected 2020 to be rejected above MaxYear")
+	}
+}
+
+func TestClientMinCompleteness(t *testing.T) {
+	files := map[string]string{
+		"1219rh.txt": hourlyCSV(2019, 300), // truncated after day 300
+		"1221rh.txt": hourlyCSV(2021, 9),
+	}
+	client, _ := newStubClient(time.Date(2021, time.January, 11, 0, 0, 0, 0, time.UTC), files)
+
+	if _, err := client.DownloadHourlyData(PhoenixGreenway, 2019); err != nil {
+		t.Errorf("without a tolerance the short year should pass: %v", err)
+	}
+
+	client.MinCompleteness = 0.95
+	if _, err := client.DownloadHourlyData(PhoenixGreenway, 2019); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("300 of 365 days: err = %v, want ErrIncomplete", err)
+	}
+	// Nine of the ten completed days of the current year clears 0.9.
+	client.MinCompleteness = 0.9
+	if data, err := client.DownloadHourlyData(PhoenixGreenway, 2021); err != nil || len(data) != 9*24 {
+		t.Errorf("current year: got %d records, err %v", len(data), err)
+	}
+	client.MinCompleteness = 0.8
+	if _, err := client.DownloadHourlyData(PhoenixGreenway, 2019); err != nil {
+		t.Errorf("300 of 365 days clears 0.8: %v", err)
+	}
+}
+
+func TestClientRefreshHourlyData(t *testing.T) {
+	files := map[string]string{"1221rh.txt": hourlyCSV(2021, 2)}
+	client, _ := newStubClient(time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC), files)
+
+	cached, err := client.DownloadHourlyData(PhoenixGreenway, 2021)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached = cached[:30]
+	cached[0].AirTemperature = 99 // a provisional value AZMET later corrected
+
+	files["1221rh.txt"] = hourlyCSV(2021, 3)
+	refreshed, err := client.RefreshHourlyData(PhoenixGreenway, 2021, cached)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refreshed) != 3*24 {
+		t.Errorf("got %d records, want %d with no duplicates", len(refreshed), 3*24)
+	}
+	if refreshed[0].AirTemperature != 20 {
+		t.Errorf("cached value %v was not replaced by the fresh 20", refreshed[0].AirTemperature)
+	}
+
+	delete(files, "1221rh.txt")
+	if kept, err := client.RefreshHourlyData(PhoenixGreenway, 2021, cached); err == nil || len(kept) != len(cached) {
+		t.Errorf("failed refresh: got %d records, err %v; want the cached records and an error", len(kept), err)
+	}
+}
+
+func TestClientHeaders(t *testing.T) {
+	files := map[string]string{"1220rh.txt": hourlyCSV(2020, 1)}
+	client, transport := newStubClient(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC), files)
+	client.Headers = http.Header{
+		"User-Agent": {"azmet-test/1.0 (ops@example.com)"},
+		"X-Trace":    {"a", "b"},
+	}
+
+	if _, err := client.DownloadHourlyData(PhoenixGreenway, 2020); err != nil {
+		t.Fatal(err)
+	}
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	header := transport.requests[0].Header
+	if got := header.Get("User-Agent"); got != "azmet-test/1.0 (ops@example.com)" {
+		t.Errorf("User-Agent = %q", got)
+	}
+	if got := header.Values("X-Trace"); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("X-Trace = %v, want both values", got)
+	}
+}
+
+// sequenceTransport answers each request with the next status in statuses,
+// repeating the last one once they run out.
+type sequenceTransport struct {
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	calls    int
+}
+
+func (s *sequenceTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.statuses[len(s.statuses)-1]
+	if s.calls < len(s.statuses) {
+		status = s.statuses[s.calls]
+	}
+	s.calls++
+	body := ""
+	if status == http.StatusOK {
+		body = hourlyCSV(2020, 1)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     s.header.Clone(),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+func TestClientRetries(t *testing.T) {
+	newClient := func(transport *sequenceTransport) *Client {
+		client := NewClient()
+		client.HTTPClient.Transport = transport
+		client.Now = func() time.Time { return time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC) }
+		client.MaxRetries = 3
+		client.RetryDelay = time.Millisecond
+		return client
+	}
+
+	// A 429 asking for an hour's pause is clamped to MaxRetryDelay.
+	limited := &sequenceTransport{
+		statuses: []int{http.StatusTooManyRequests, http.StatusOK},
+		header:   http.Header{"Retry-After": {"3600"}},
+	}
+	client := newClient(limited)
+	client.RetryStatusCodes = []int{http.StatusTooManyRequests}
+	client.MaxRetryDelay = 20 * time.Millisecond
+	start := time.Now()
+	if _, err := client.DownloadHourlyData(PhoenixGreenway, 2020); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("waited %v despite a 20ms MaxRetryDelay", elapsed)
+	}
+	if limited.calls != 2 {
+		t.Errorf("429 then 200 took %d requests, want 2", limited.calls)
+	}
+
+	// A 400 is not retryable and fails at once.
+	bad := &sequenceTransport{statuses: []int{http.StatusBadRequest}}
+	if _, err := newClient(bad).DownloadHourlyData(PhoenixGreenway, 2020); err == nil {
+		t.Errorf("expected an error for a 400 response")
+	}
+	if bad.calls != 1 {
+		t.Errorf("400 was requested %d times, want 1", bad.calls)
+	}
+
+	// Persistent 5xx responses give up after MaxRetries.
+	down := &sequenceTransport{statuses: []int{http.StatusBadGateway}}
+	if _, err := newClient(down).DownloadHourlyData(PhoenixGreenway, 2020); err == nil {
+		t.Errorf("expected an error once retries are exhausted")
+	}
+	if down.calls != 4 {
+		t.Errorf("502 was requested %d times, want 4", down.calls)
+	}
+}
+
+func TestClientDownloadRawDataReparse(t *testing.T) {
+	files := map[string]string{"1220rh.txt": hourlyCSV(2020, 2)}
+	client, transport := newStubClient(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC), files)
+
+	raw, err := client.DownloadRawData(PhoenixGreenway, 2020)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != files["1220rh.txt"] {
+		t.Fatalf("raw bytes differ from the served file")
+	}
+
+	first, err := ParseHourlyData(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ParseHourlyData(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 48 || len(second) != len(first) {
+		t.Fatalf("parsed %d then %d records, want 48 both times", len(first), len(second))
+	}
+	for i := range first {
+		if !sameRecord(first[i], second[i]) {
+			t.Fatalf("record %d differs between parses", i)
+		}
+	}
+	if n := transport.requested("1220rh.txt"); n != 1 {
+		t.Errorf("file fetched %d times, want 1", n)
+	}
+}
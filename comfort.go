@@ -0,0 +1,207 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// WBGTEstimate approximates the outdoor wet-bulb globe temperature in degrees
+// Celsius as 0.7 Twb + 0.2 Tg + 0.1 Tdb. The natural wet-bulb is taken to be
+// the psychrometric WetBulb, and the globe temperature is approximated as the
+// air temperature raised by solar load and reduced by wind:
+//
+//	Tg = Ta + S / (70 (1 + 0.5 u))
+//
+// with S the mean irradiance in W/m² and u the wind speed in m/s. Both are
+// rough stand-ins for instruments AZMET does not carry, so treat the result
+// as a screening value rather than a compliance measurement. It returns
+// MissingValue when any of the inputs is missing.
+func (data HourlyWeatherData) WBGTEstimate() float32 {
+	if IsMissing(data.AirTemperature) || IsMissing(data.RelativeHumidity) ||
+		IsMissing(data.SolarRadiation) || IsMissing(data.WindSpeedAverage) {
+		return MissingValue
+	}
+	ta := float64(data.AirTemperature)
+	wind := float64(data.WindSpeedAverage)
+	if wind < 0 {
+		wind = 0
+	}
+	globe := ta + solarRadiationWatts(data.SolarRadiation)/(70*(1+0.5*wind))
+	return float32(0.7*float64(data.WetBulb()) + 0.2*globe + 0.1*ta)
+}
+
+// DefaultCoolerEfficiency is a typical saturation efficiency for a direct
+// evaporative (swamp) cooler pad.
+const DefaultCoolerEfficiency float32 = 0.85
+
+// EvaporativeCoolingPotential returns the supply air temperature in degrees
+// Celsius an evaporative cooler can reach, moving the dry-bulb temperature
+// toward the WetBulb temperature by the given saturation efficiency (0-1). It
+// returns MissingValue when AirTemperature or RelativeHumidity is missing.
+func (data HourlyWeatherData) EvaporativeCoolingPotential(efficiency float32) float32 {
+	if IsMissing(data.AirTemperature) || IsMissing(data.RelativeHumidity) {
+		return MissingValue
+	}
+	return data.AirTemperature - efficiency*(data.AirTemperature-data.WetBulb())
+}
+
+// HeatIndex returns the NWS heat index in degrees Celsius, using Steadman's
+// simple formula below 80 °F and the Rothfusz regression with its low and high
+// humidity adjustments above. It returns MissingValue when AirTemperature or
+// RelativeHumidity is missing.
+func (data HourlyWeatherData) HeatIndex() float32 {
+	if IsMissing(data.AirTemperature) || IsMissing(data.RelativeHumidity) {
+		return MissingValue
+	}
+	t := float64(celsiusToFahrenheit(data.AirTemperature))
+	rh := float64(data.RelativeHumidity)
+
+	hi := 0.5 * (t + 61 + (t-68)*1.2 + rh*0.094)
+	if (hi+t)/2 >= 80 {
+		hi = -42.379 + 2.04901523*t + 10.14333127*rh - 0.22475541*t*rh -
+			0.00683783*t*t - 0.05481717*rh*rh + 0.00122874*t*t*rh +
+			0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+		if rh < 13 && t >= 80 && t <= 112 {
+			hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+		} else if rh > 85 && t >= 80 && t <= 87 {
+			hi += (rh - 85) / 10 * (87 - t) / 5
+		}
+	}
+	return fahrenheitToCelsius(float32(hi))
+}
+
+// DefaultMiseryHeatIndexF is the heat index, in degrees Fahrenheit, above which
+// an hour counts toward MiseryHours by default.
+const DefaultMiseryHeatIndexF float32 = 105
+
+func isMiseryHour(rec HourlyWeatherData, heatIndexThreshold float32) bool {
+	hi := rec.HeatIndex()
+	return !IsMissing(hi) && celsiusToFahrenheit(hi) > heatIndexThreshold
+}
+
+// MiseryHours counts hours whose HeatIndex exceeds heatIndexThreshold, given
+// in degrees Fahrenheit.
+func MiseryHours(data []HourlyWeatherData, heatIndexThreshold float32) int {
+	hours := 0
+	for _, rec := range data {
+		if isMiseryHour(rec, heatIndexThreshold) {
+			hours++
+		}
+	}
+	return hours
+}
+
+// MiseryHoursByDay is MiseryHours broken out by day of year.
+func MiseryHoursByDay(data []HourlyWeatherData, heatIndexThreshold float32) map[int]int {
+	days := make(map[int]int)
+	for _, rec := range data {
+		if isMiseryHour(rec, heatIndexThreshold) {
+			days[rec.Day]++
+		}
+	}
+	return days
+}
+
+// WetBulbDepression is the difference between AirTemperature and WetBulb in
+// degrees Celsius. The larger it is, the more an evaporative cooler can cool.
+// It returns MissingValue when AirTemperature or RelativeHumidity is missing.
+func (data HourlyWeatherData) WetBulbDepression() float32 {
+	if IsMissing(data.AirTemperature) || IsMissing(data.RelativeHumidity) {
+		return MissingValue
+	}
+	return data.AirTemperature - data.WetBulb()
+}
+
+// SummerWetBulbDepressionFraction returns the fraction of valid June through
+// August hours whose WetBulbDepression exceeds threshold (°C).
+func SummerWetBulbDepressionFraction(data []HourlyWeatherData, threshold float32) float64 {
+	total, above := 0, 0
+	for _, rec := range data {
+		month := rec.Time.Month()
+		if month < time.June || month > time.August {
+			continue
+		}
+		if IsMissing(rec.AirTemperature) || IsMissing(rec.RelativeHumidity) {
+			continue
+		}
+		total++
+		if rec.WetBulbDepression() > threshold {
+			above++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(above) / float64(total)
+}
+
+// EffectiveTemperature approximates how warm it feels in the open, in degrees
+// Celsius, using Steadman's (1994) apparent temperature with radiation:
+//
+//	AT = Ta + 0.348 e - 0.70 u + 0.70 Q / (u + 10) - 4.25
+//
+// where e is the vapor pressure in hPa, u the wind speed in m/s and Q the
+// radiation absorbed per unit of body surface in W/m². Q is taken as 0.2 of
+// the mean global irradiance over the hour, a rough allowance for the share
+// of the body facing the sun and its absorptivity. It returns MissingValue
+// when AirTemperature, wind, radiation or both humidity measures are missing.
+func (data HourlyWeatherData) EffectiveTemperature() float32 {
+	if IsMissing(data.AirTemperature) || IsMissing(data.WindSpeedAverage) || IsMissing(data.SolarRadiation) ||
+		(IsMissing(data.VaporPressureActual) && IsMissing(data.RelativeHumidity)) {
+		return MissingValue
+	}
+	e := float64(data.VaporPressureActual)
+	if IsMissing(data.VaporPressureActual) {
+		e = float64(data.RelativeHumidity) / 100 * saturationVaporPressure(data.AirTemperature)
+	}
+	wind := math.Max(0, float64(data.WindSpeedAverage))
+	q := 0.2 * solarRadiationWatts(data.SolarRadiation)
+	at := float64(data.AirTemperature) + 0.348*e*10 - 0.70*wind + 0.70*q/(wind+10) - 4.25
+	return float32(at)
+}
+
+// coolingSolarWeight converts mean irradiance in W/m² into equivalent degrees
+// Fahrenheit of cooling demand, so full sun of about 1000 W/m² adds 10 °F.
+const coolingSolarWeight = 0.01
+
+// CoolingLoadProxy is a relative indicator of the hour's cooling demand: the
+// degrees Fahrenheit AirTemperature sits above baseF, floored at zero, plus
+// 0.01 °F per W/m² of mean solar irradiance to account for solar gain through
+// windows and roofs. It is meant for comparing hours, not sizing equipment.
+// Missing radiation counts as none; a missing AirTemperature gives
+// MissingValue.
+func (data HourlyWeatherData) CoolingLoadProxy(baseF float32) float32 {
+	if IsMissing(data.AirTemperature) {
+		return MissingValue
+	}
+	excess := celsiusToFahrenheit(data.AirTemperature) - baseF
+	if excess < 0 {
+		excess = 0
+	}
+	solar := float32(0)
+	if !IsMissing(data.SolarRadiation) && data.SolarRadiation > 0 {
+		solar = float32(coolingSolarWeight * solarRadiationWatts(data.SolarRadiation))
+	}
+	return excess + solar
+}
+
+// ComfortFraction returns the fraction of valid hours whose AirTemperature is
+// within [minF, maxF] degrees Fahrenheit and whose RelativeHumidity is below
+// maxRH percent. Hours missing either value are excluded.
+func ComfortFraction(data []HourlyWeatherData, minF, maxF, maxRH float32) float64 {
+	total, comfortable := 0, 0
+	for _, rec := range data {
+		if IsMissing(rec.AirTemperature) || IsMissing(rec.RelativeHumidity) {
+			continue
+		}
+		total++
+		tempF := celsiusToFahrenheit(rec.AirTemperature)
+		if tempF >= minF && tempF <= maxF && rec.RelativeHumidity < maxRH {
+			comfortable++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(comfortable) / float64(total)
+}
@@ -0,0 +1,237 @@
+package main
+
+import "testing"
+
+func TestWBGTEstimate(t *testing.T) {
+	dry := HourlyWeatherData{AirTemperature: 40, RelativeHumidity: 10}
+	humid := HourlyWeatherData{AirTemperature: 40, RelativeHumidity: 60}
+
+	// Without sun the globe reads the air temperature.
+	for _, rec := range []HourlyWeatherData{dry, humid} {
+		want := 0.7*float64(rec.WetBulb()) + 0.3*40
+		if got := rec.WBGTEstimate(); !approxEqual(float64(got), want, 1e-4) {
+			t.Errorf("RH %v: WBGTEstimate = %v, want %v", rec.RelativeHumidity, got, want)
+		}
+	}
+	if dry.WBGTEstimate() >= humid.WBGTEstimate() {
+		t.Errorf("expected humid air to have the higher WBGT")
+	}
+
+	sunny := dry
+	sunny.SolarRadiation = 3.6 // 1000 W/m²
+	if got, want := sunny.WBGTEstimate()-dry.WBGTEstimate(), float32(0.2*1000.0/70); !approxEqual(float64(got), float64(want), 1e-3) {
+		t.Errorf("solar load raised WBGT by %v, want %v", got, want)
+	}
+	sunny.WindSpeedAverage = 4
+	if got, want := sunny.WBGTEstimate()-dry.WBGTEstimate(), float32(0.2*1000.0/(70*3)); !approxEqual(float64(got), float64(want), 1e-3) {
+		t.Errorf("wind reduced the solar load to %v, want %v", got, want)
+	}
+
+	missing := dry
+	missing.RelativeHumidity = MissingValue
+	if got := missing.WBGTEstimate(); got != MissingValue {
+		t.Errorf("missing humidity: WBGTEstimate = %v, want MissingValue", got)
+	}
+}
+
+func TestEvaporativeCoolingPotential(t *testing.T) {
+	dry := HourlyWeatherData{AirTemperature: 40, RelativeHumidity: 10}
+	humid := HourlyWeatherData{AirTemperature: 40, RelativeHumidity: 70}
+
+	dryDrop := 40 - dry.EvaporativeCoolingPotential(DefaultCoolerEfficiency)
+	humidDrop := 40 - humid.EvaporativeCoolingPotential(DefaultCoolerEfficiency)
+	if dryDrop < 15 {
+		t.Errorf("dry air cooled by only %v °C", dryDrop)
+	}
+	if humidDrop > 6 {
+		t.Errorf("humid air cooled by %v °C, expected a small drop", humidDrop)
+	}
+
+	if got, want := dry.EvaporativeCoolingPotential(1), dry.WetBulb(); got != want {
+		t.Errorf("full efficiency reached %v, want the wet bulb %v", got, want)
+	}
+	if got := dry.EvaporativeCoolingPotential(0); got != 40 {
+		t.Errorf("zero efficiency changed the temperature to %v", got)
+	}
+
+	missing := HourlyWeatherData{AirTemperature: MissingValue, RelativeHumidity: 10}
+	if got := missing.EvaporativeCoolingPotential(DefaultCoolerEfficiency); got != MissingValue {
+		t.Errorf("missing temperature: got %v, want MissingValue", got)
+	}
+}
+
+func TestHeatIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		temp, rh float32
+		want     float32
+	}{
+		// Steadman's formula: 0.5 * (70 + 61 + 2.4 + 4.7) = 69.05 °F.
+		{"mild", fahrenheitToCelsius(70), 50, fahrenheitToCelsius(69.05)},
+		// The Rothfusz regression gives 94.6 °F, as in the NWS table.
+		{"hot", fahrenheitToCelsius(90), 50, fahrenheitToCelsius(94.597)},
+		{"missing temperature", MissingValue, 50, MissingValue},
+		{"missing humidity", 30, MissingValue, MissingValue},
+		{"all missing", MissingValue, MissingValue, MissingValue},
+	}
+	for _, tt := range tests {
+		rec := HourlyWeatherData{AirTemperature: tt.temp, RelativeHumidity: tt.rh}
+		if got := rec.HeatIndex(); !approxEqual(float64(got), float64(tt.want), 0.01) {
+			t.Errorf("%s: HeatIndex = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMiseryHours(t *testing.T) {
+	hot := fahrenheitToCelsius(100)
+	hours := []struct {
+		day      int
+		temp, rh float32
+	}{
+		{180, hot, 40}, // heat index about 109 °F
+		{180, hot, 15}, // dry heat, about 96 °F
+		{181, 30, 40},  // warm, about 84 °F
+		{181, hot, 45}, // heat index about 114 °F
+		{181, hot, MissingValue},
+	}
+	var data []HourlyWeatherData
+	for i, h := range hours {
+		rec := testRecord(t, 2020, h.day, i+1)
+		rec.AirTemperature, rec.RelativeHumidity = h.temp, h.rh
+		data = append(data, rec)
+	}
+
+	if got := MiseryHours(data, DefaultMiseryHeatIndexF); got != 2 {
+		t.Errorf("MiseryHours = %d, want 2", got)
+	}
+	if got := MiseryHours(data, 90); got != 3 {
+		t.Errorf("MiseryHours at 90 °F = %d, want 3", got)
+	}
+	byDay := MiseryHoursByDay(data, DefaultMiseryHeatIndexF)
+	if byDay[180] != 1 || byDay[181] != 1 || len(byDay) != 2 {
+		t.Errorf("MiseryHoursByDay = %v, want one hour on each day", byDay)
+	}
+}
+
+func TestWetBulbDepression(t *testing.T) {
+	dry := HourlyWeatherData{AirTemperature: 40, RelativeHumidity: 10}
+	humid := HourlyWeatherData{AirTemperature: 40, RelativeHumidity: 80}
+	saturated := HourlyWeatherData{AirTemperature: 25, RelativeHumidity: 99}
+
+	if got := dry.WetBulbDepression(); got < 18 {
+		t.Errorf("dry depression = %v, want about 20 °C", got)
+	}
+	if got := humid.WetBulbDepression(); got > 4 {
+		t.Errorf("humid depression = %v, want under 4 °C", got)
+	}
+	if got := saturated.WetBulbDepression(); !approxEqual(float64(got), 0, 0.5) {
+		t.Errorf("near-saturated depression = %v, want about 0", got)
+	}
+	missing := HourlyWeatherData{AirTemperature: 40, RelativeHumidity: MissingValue}
+	if got := missing.WetBulbDepression(); got != MissingValue {
+		t.Errorf("missing humidity: got %v, want MissingValue", got)
+	}
+
+	var data []HourlyWeatherData
+	for i, rh := range []float32{10, 15, 80, MissingValue} {
+		rec := testRecord(t, 2020, 190, i+1) // early July
+		rec.AirTemperature, rec.RelativeHumidity = 40, rh
+		data = append(data, rec)
+	}
+	winter := testRecord(t, 2020, 20, 1)
+	winter.AirTemperature, winter.RelativeHumidity = 20, 10
+	data = append(data, winter)
+	if got := SummerWetBulbDepressionFraction(data, 10); !approxEqual(got, 2.0/3, 1e-9) {
+		t.Errorf("SummerWetBulbDepressionFraction = %v, want 2/3", got)
+	}
+}
+
+func TestEffectiveTemperature(t *testing.T) {
+	// Shade and calm: AT = 30 + 0.348 × 20 hPa - 4.25.
+	shade := HourlyWeatherData{AirTemperature: 30, VaporPressureActual: 2, RelativeHumidity: MissingValue}
+	if got := shade.EffectiveTemperature(); !approxEqual(float64(got), 32.71, 0.01) {
+		t.Errorf("calm shade = %v, want 32.71", got)
+	}
+
+	breezy := shade
+	breezy.WindSpeedAverage = 5
+	if got := breezy.EffectiveTemperature(); !approxEqual(float64(got), 29.21, 0.01) {
+		t.Errorf("5 m/s breeze = %v, want 29.21", got)
+	}
+
+	sunny := shade
+	sunny.SolarRadiation = 3.6 // 1000 W/m², so Q = 200 W/m²
+	if got := sunny.EffectiveTemperature(); !approxEqual(float64(got), 32.71+14, 0.01) {
+		t.Errorf("calm sun = %v, want %v", got, 32.71+14)
+	}
+
+	dry := HourlyWeatherData{AirTemperature: 30, RelativeHumidity: 10, VaporPressureActual: MissingValue}
+	humid := HourlyWeatherData{AirTemperature: 30, RelativeHumidity: 80, VaporPressureActual: MissingValue}
+	if d, h := dry.EffectiveTemperature(), humid.EffectiveTemperature(); d >= 30 || h <= 35 {
+		t.Errorf("dry = %v, humid = %v; want dry air to feel cooler and humid air hotter than 30", d, h)
+	}
+
+	missing := dry
+	missing.RelativeHumidity = MissingValue
+	if got := missing.EffectiveTemperature(); got != MissingValue {
+		t.Errorf("missing humidity: got %v, want MissingValue", got)
+	}
+}
+
+func TestCoolingLoadProxy(t *testing.T) {
+	at := func(tempF, solar float32) float32 {
+		rec := HourlyWeatherData{AirTemperature: fahrenheitToCelsius(tempF), SolarRadiation: solar}
+		return rec.CoolingLoadProxy(75)
+	}
+
+	if got := at(70, 0); got != 0 {
+		t.Errorf("below base at night = %v, want 0", got)
+	}
+	if got := at(95, 0); !approxEqual(float64(got), 20, 1e-3) {
+		t.Errorf("95 °F at night = %v, want 20", got)
+	}
+	if at(100, 0) <= at(90, 0) {
+		t.Errorf("proxy did not rise with temperature")
+	}
+	// Full sun of 1000 W/m² adds 10 °F.
+	if got := at(95, 3.6) - at(95, 0); !approxEqual(float64(got), 10, 1e-3) {
+		t.Errorf("full sun added %v, want 10", got)
+	}
+	if at(70, 1.8) <= at(70, 0) {
+		t.Errorf("proxy did not rise with solar gain below the base temperature")
+	}
+	if got := at(95, MissingValue); !approxEqual(float64(got), 20, 1e-3) {
+		t.Errorf("missing radiation = %v, want 20", got)
+	}
+
+	missing := HourlyWeatherData{AirTemperature: MissingValue, SolarRadiation: 3}
+	if got := missing.CoolingLoadProxy(75); got != MissingValue {
+		t.Errorf("missing temperature = %v, want MissingValue", got)
+	}
+}
+
+func TestComfortFraction(t *testing.T) {
+	hours := []struct{ tempF, rh float32 }{
+		{72, 30}, // comfortable
+		{68, 59}, // comfortable, at the lower bound
+		{80, 40}, // comfortable, at the upper bound
+		{95, 20}, // too hot
+		{60, 30}, // too cool
+		{72, 65}, // too humid
+		{72, MissingValue},
+	}
+	var data []HourlyWeatherData
+	for _, h := range hours {
+		data = append(data, HourlyWeatherData{AirTemperature: fahrenheitToCelsius(h.tempF), RelativeHumidity: h.rh})
+	}
+
+	if got := ComfortFraction(data, 68, 80, 60); !approxEqual(got, 0.5, 1e-9) {
+		t.Errorf("ComfortFraction = %v, want 0.5", got)
+	}
+	if got := ComfortFraction(data, 90, 100, 60); !approxEqual(got, 1.0/6, 1e-9) {
+		t.Errorf("hot band ComfortFraction = %v, want 1/6", got)
+	}
+	if got := ComfortFraction(nil, 68, 80, 60); got != 0 {
+		t.Errorf("empty ComfortFraction = %v, want 0", got)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"time"
+)
+
+type DayOfYearComparison struct {
+	// Day is the day of year in a's numbering.
+	Day  int
+	Hour int
+	A    HourlyWeatherData
+	B    HourlyWeatherData
+	// Differences holds B minus A for each numeric field present in both
+	// records, keyed by field name.
+	Differences map[string]float32
+}
+
+type calendarHour struct {
+	Month time.Month
+	Day   int
+	Hour  int
+}
+
+func calendarHourOf(rec HourlyWeatherData) calendarHour {
+	date := time.Date(rec.Year, 1, rec.Day, 0, 0, 0, 0, time.UTC)
+	return calendarHour{date.Month(), date.Day(), rec.Hour}
+}
+
+// CompareYears aligns two years of records by calendar date and hour and
+// reports per-field differences. Aligning on the calendar rather than the raw
+// day-of-year number keeps March onwards matched when only one of the years
+// is a leap year; February 29 has no counterpart in a common year and is left
+// out of the result.
+func CompareYears(a, b []HourlyWeatherData) []DayOfYearComparison {
+	byHour := make(map[calendarHour]HourlyWeatherData, len(b))
+	for _, rec := range b {
+		byHour[calendarHourOf(rec)] = rec
+	}
+
+	comparisons := make([]DayOfYearComparison, 0)
+	for _, recA := range a {
+		recB, ok := byHour[calendarHourOf(recA)]
+		if !ok {
+			continue
+		}
+		comparisons = append(comparisons, DayOfYearComparison{
+			Day:         recA.Day,
+			Hour:        recA.Hour,
+			A:           recA,
+			B:           recB,
+			Differences: fieldDifferences(recA, recB),
+		})
+	}
+	return comparisons
+}
+
+func fieldDifferences(a, b HourlyWeatherData) map[string]float32 {
+	diffs := make(map[string]float32)
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if va.Field(i).Kind() != reflect.Float32 {
+			continue
+		}
+		x, y := float32(va.Field(i).Float()), float32(vb.Field(i).Float())
+		if IsMissing(x) || IsMissing(y) {
+			continue
+		}
+		diffs[va.Type().Field(i).Name] = y - x
+	}
+	return diffs
+}
+
+// UrbanHeatIslandIntensity returns the mean night-time AirTemperature excess
+// of an urban station over a nearby rural one, matching records by Time.
+// Night is judged with IsDaytime at urbanStation's coordinates. hours is the
+// number of paired night hours; with none, or when urbanStation is not in
+// the station table, the intensity is zero.
+func UrbanHeatIslandIntensity(urban, rural []HourlyWeatherData, urbanStation WeatherStation) (intensity float32, hours int) {
+	info, ok := LookupStation(urbanStation)
+	if !ok {
+		return 0, 0
+	}
+	ruralByTime := ToTimeMap(rural)
+	var sum float32
+	for _, u := range urban {
+		r, ok := ruralByTime[u.Time.UTC()]
+		if !ok || IsMissing(u.AirTemperature) || IsMissing(r.AirTemperature) {
+			continue
+		}
+		if IsDaytime(u.Time, info.Latitude, info.Longitude) {
+			continue
+		}
+		sum += u.AirTemperature - r.AirTemperature
+		hours++
+	}
+	if hours == 0 {
+		return 0, 0
+	}
+	return sum / float32(hours), hours
+}
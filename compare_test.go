@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestCompareYearsLeapYear(t *testing.T) {
+	var leap, common []HourlyWeatherData
+	for day := 58; day <= 61; day++ { // Feb 27 to Mar 1 in 2020
+		rec := testRecord(t, 2020, day, 12)
+		rec.AirTemperature = float32(day)
+		leap = append(leap, rec)
+	}
+	for day := 58; day <= 60; day++ { // Feb 27 to Mar 1 in 2021
+		rec := testRecord(t, 2021, day, 12)
+		rec.AirTemperature = float32(day) + 10
+		rec.RelativeHumidity = MissingValue
+		common = append(common, rec)
+	}
+
+	comparisons := CompareYears(leap, common)
+	wantDays := []int{58, 59, 61} // February 29, day 60 of 2020, is dropped
+	if len(comparisons) != len(wantDays) {
+		t.Fatalf("got %d comparisons, want %d", len(comparisons), len(wantDays))
+	}
+	for i, c := range comparisons {
+		if c.Day != wantDays[i] {
+			t.Errorf("comparison %d: Day = %d, want %d", i, c.Day, wantDays[i])
+		}
+		// From March 1 onwards the common year's day number is one lower.
+		want := float32(10)
+		if c.Day == 61 {
+			want = 9
+		}
+		if got := c.Differences["AirTemperature"]; got != want {
+			t.Errorf("day %d: AirTemperature difference = %v, want %v", c.Day, got, want)
+		}
+		if _, ok := c.Differences["RelativeHumidity"]; ok {
+			t.Errorf("day %d: missing RelativeHumidity should have no difference", c.Day)
+		}
+	}
+}
+
+func TestUrbanHeatIslandIntensity(t *testing.T) {
+	var urban, rural []HourlyWeatherData
+	for hour := 1; hour <= 24; hour++ {
+		r := testRecord(t, 2020, 180, hour)
+		r.AirTemperature = 30
+		u := r
+		// The city holds its heat after dark and runs slightly cooler by day.
+		if hour <= 5 || hour >= 20 {
+			u.AirTemperature = 34
+		} else {
+			u.AirTemperature = 29
+		}
+		urban = append(urban, u)
+		rural = append(rural, r)
+	}
+	urban[0].AirTemperature = MissingValue
+	rural = rural[:23] // no rural reading for the last hour
+
+	// Night at Phoenix Encanto runs from about 19:45 to 05:20 MST; the paired
+	// night hours are 2-5 and 20-23, each 4 °C warmer in the city.
+	intensity, hours := UrbanHeatIslandIntensity(urban, rural, PhoenixEncanto)
+	if hours != 8 || intensity != 4 {
+		t.Errorf("intensity %v over %d hours, want 4 over 8", intensity, hours)
+	}
+
+	if intensity, hours := UrbanHeatIslandIntensity(urban, rural, WeatherStation(99)); intensity != 0 || hours != 0 {
+		t.Errorf("unknown station: %v over %d hours, want 0", intensity, hours)
+	}
+}
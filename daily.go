@@ -0,0 +1,133 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+type DailyAggregate struct {
+	Year int
+	Day  int
+	Date time.Time
+	// Hours is the number of hourly records that went into the aggregate.
+	Hours int
+	// Temperature fields are MissingValue when the day has no valid reading.
+	MaxAirTemperature  float32
+	MinAirTemperature  float32
+	MeanAirTemperature float32
+	Precipitation      float32
+	Evapotranspiration float32
+}
+
+type dayKey struct {
+	Year int
+	Day  int
+}
+
+// groupByDay splits hourly records into per-day slices, ordered by the first
+// appearance of each day in data.
+func groupByDay(data []HourlyWeatherData) [][]HourlyWeatherData {
+	index := make(map[dayKey]int)
+	days := make([][]HourlyWeatherData, 0)
+	for _, rec := range data {
+		key := dayKey{rec.Year, rec.Day}
+		i, ok := index[key]
+		if !ok {
+			i = len(days)
+			index[key] = i
+			days = append(days, []HourlyWeatherData{})
+		}
+		days[i] = append(days[i], rec)
+	}
+	return days
+}
+
+// AggregateDaily summarizes hourly records into one DailyAggregate per day.
+// Missing observations are skipped; precipitation and evapotranspiration are
+// totals of the valid hours.
+func AggregateDaily(hourly []HourlyWeatherData) []DailyAggregate {
+	days := groupByDay(hourly)
+	daily := make([]DailyAggregate, 0, len(days))
+	for _, day := range days {
+		first := day[0]
+		agg := DailyAggregate{
+			Year:               first.Year,
+			Day:                first.Day,
+			Date:               time.Date(first.Year, 1, first.Day, 0, 0, 0, 0, first.Time.Location()),
+			Hours:              len(day),
+			MaxAirTemperature:  MissingValue,
+			MinAirTemperature:  MissingValue,
+			MeanAirTemperature: MissingValue,
+		}
+		var sum float32
+		n := 0
+		for _, rec := range day {
+			if !IsMissing(rec.AirTemperature) {
+				if n == 0 || rec.AirTemperature > agg.MaxAirTemperature {
+					agg.MaxAirTemperature = rec.AirTemperature
+				}
+				if n == 0 || rec.AirTemperature < agg.MinAirTemperature {
+					agg.MinAirTemperature = rec.AirTemperature
+				}
+				sum += rec.AirTemperature
+				n++
+			}
+			if !IsMissing(rec.Precipitation) {
+				agg.Precipitation += rec.Precipitation
+			}
+			if !IsMissing(rec.Evapotranspiration) {
+				agg.Evapotranspiration += rec.Evapotranspiration
+			}
+		}
+		if n > 0 {
+			agg.MeanAirTemperature = sum / float32(n)
+		}
+		daily = append(daily, agg)
+	}
+	return daily
+}
+
+// CountDaysWhere counts the days for which pred holds and returns their dates.
+func CountDaysWhere(daily []DailyAggregate, pred func(DailyAggregate) bool) (int, []time.Time) {
+	dates := make([]time.Time, 0)
+	for _, day := range daily {
+		if pred(day) {
+			dates = append(dates, day.Date)
+		}
+	}
+	return len(dates), dates
+}
+
+type WeekKey struct {
+	ISOYear int
+	ISOWeek int
+}
+
+// GroupByWeek groups records by the ISO 8601 week of their Year and Day, so
+// early January days can belong to the last week of the previous year and
+// late December days to week 1 of the next. Records keep their input order
+// within each week.
+func GroupByWeek(data []HourlyWeatherData) map[WeekKey][]HourlyWeatherData {
+	weeks := make(map[WeekKey][]HourlyWeatherData)
+	for _, rec := range data {
+		year, week := time.Date(rec.Year, 1, rec.Day, 0, 0, 0, 0, time.UTC).ISOWeek()
+		key := WeekKey{year, week}
+		weeks[key] = append(weeks[key], rec)
+	}
+	return weeks
+}
+
+// SortedWeekKeys returns the keys of weeks in chronological order.
+func SortedWeekKeys(weeks map[WeekKey][]HourlyWeatherData) []WeekKey {
+	keys := make([]WeekKey, 0, len(weeks))
+	for key := range weeks {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ISOYear != keys[j].ISOYear {
+			return keys[i].ISOYear < keys[j].ISOYear
+		}
+		return keys[i].ISOWeek < keys[j].ISOWeek
+	})
+	return keys
+}
@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestCountDaysWhere(t *testing.T) {
+	var hourly []HourlyWeatherData
+	// The last day's dawn reading is missing, so its low comes from the
+	// remaining hours.
+	lows := []float32{5, -1, 8, 3, MissingValue}
+	for i, low := range lows {
+		for hour := 1; hour <= 24; hour++ {
+			rec := testRecord(t, 2020, 60+i, hour)
+			rec.AirTemperature = 15
+			if hour == 6 {
+				rec.AirTemperature = low
+			}
+			hourly = append(hourly, rec)
+		}
+	}
+	for hour := 1; hour <= 24; hour++ { // frost free but cool
+		rec := testRecord(t, 2020, 65, hour)
+		rec.AirTemperature = 10
+		hourly = append(hourly, rec)
+	}
+	daily := AggregateDaily(hourly)
+
+	// Growing days: warm on average and no frost.
+	growing := func(day DailyAggregate) bool {
+		return !IsMissing(day.MinAirTemperature) && day.MinAirTemperature > 0 && day.MeanAirTemperature > 14
+	}
+	n, dates := CountDaysWhere(daily, growing)
+	if n != 4 || len(dates) != 4 {
+		t.Fatalf("counted %d days with %d dates, want 4", n, len(dates))
+	}
+	for i, day := range []int{60, 62, 63, 64} {
+		if dates[i].YearDay() != day {
+			t.Errorf("date %d is day %d, want %d", i, dates[i].YearDay(), day)
+		}
+	}
+
+	if n, dates := CountDaysWhere(daily, func(DailyAggregate) bool { return false }); n != 0 || len(dates) != 0 {
+		t.Errorf("never-true predicate counted %d days", n)
+	}
+}
+
+func TestGroupByWeekYearBoundary(t *testing.T) {
+	days := [][2]int{
+		{2019, 364}, // Monday December 30, 2019: week 1 of 2020
+		{2020, 366}, // Thursday December 31, 2020: week 53 of 2020
+		{2021, 1},   // Friday January 1, 2021: still week 53 of 2020
+		{2021, 3},   // Sunday January 3, 2021: last day of that week
+		{2021, 4},   // Monday January 4, 2021: week 1 of 2021
+	}
+	var data []HourlyWeatherData
+	for _, d := range days {
+		data = append(data, testRecord(t, d[0], d[1], 12))
+	}
+
+	weeks := GroupByWeek(data)
+	keys := SortedWeekKeys(weeks)
+	want := []WeekKey{{2020, 1}, {2020, 53}, {2021, 1}}
+	if len(keys) != len(want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("key %d = %v, want %v", i, keys[i], want[i])
+		}
+	}
+	if late := weeks[WeekKey{2020, 53}]; len(late) != 3 || late[0].Year != 2020 || late[2].Day != 3 {
+		t.Errorf("week 53 of 2020 = %v, want the three records in input order", late)
+	}
+}
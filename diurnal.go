@@ -0,0 +1,58 @@
+package main
+
+import "math"
+
+// minDiurnalSamples is the fewest valid hours FitDiurnalTemperature accepts.
+const minDiurnalSamples = 12
+
+// FitDiurnalTemperature fits AirTemperature to a 24-hour sinusoid by least
+// squares,
+//
+//	T(h) = mean + amplitude cos(2π (h - phaseHours) / 24)
+//
+// where h is the record's Hour and phaseHours, in [0, 24), is the hour of the
+// fitted maximum. ok is false when fewer than 12 valid hours are available.
+func FitDiurnalTemperature(hourly []HourlyWeatherData) (mean, amplitude, phaseHours float32, ok bool) {
+	const omega = 2 * math.Pi / 24
+
+	// Normal equations for T = m + a cos(ωh) + b sin(ωh).
+	var n, sc, ss, scc, sss, scs, sy, syc, sys float64
+	for _, rec := range hourly {
+		if IsMissing(rec.AirTemperature) {
+			continue
+		}
+		c, s := math.Cos(omega*float64(rec.Hour)), math.Sin(omega*float64(rec.Hour))
+		y := float64(rec.AirTemperature)
+		n++
+		sc += c
+		ss += s
+		scc += c * c
+		sss += s * s
+		scs += c * s
+		sy += y
+		syc += y * c
+		sys += y * s
+	}
+	if n < minDiurnalSamples {
+		return 0, 0, 0, false
+	}
+
+	det := det3(n, sc, ss, sc, scc, scs, ss, scs, sss)
+	if det == 0 {
+		return 0, 0, 0, false
+	}
+	m := det3(sy, sc, ss, syc, scc, scs, sys, scs, sss) / det
+	a := det3(n, sy, ss, sc, syc, scs, ss, sys, sss) / det
+	b := det3(n, sc, sy, sc, scc, syc, ss, scs, sys) / det
+
+	phase := math.Atan2(b, a) / omega
+	if phase < 0 {
+		phase += 24
+	}
+	return float32(m), float32(math.Hypot(a, b)), float32(phase), true
+}
+
+// det3 returns the determinant of the row-major 3x3 matrix.
+func det3(a, b, c, d, e, f, g, h, i float64) float64 {
+	return a*(e*i-f*h) - b*(d*i-f*g) + c*(d*h-e*g)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitDiurnalTemperature(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var hourly []HourlyWeatherData
+	for day := 180; day <= 182; day++ {
+		for hour := 1; hour <= 24; hour++ {
+			rec := testRecord(t, 2020, day, hour)
+			// 28 °C mean, 8 °C amplitude, peaking at 15:00, with noise.
+			clean := 28 + 8*math.Cos(2*math.Pi*float64(hour-15)/24)
+			rec.AirTemperature = float32(clean + rng.NormFloat64()*0.5)
+			hourly = append(hourly, rec)
+		}
+	}
+	hourly[10].AirTemperature = MissingValue
+
+	mean, amplitude, phase, ok := FitDiurnalTemperature(hourly)
+	if !ok {
+		t.Fatal("fit failed")
+	}
+	if !approxEqual(float64(mean), 28, 0.2) || !approxEqual(float64(amplitude), 8, 0.3) || !approxEqual(float64(phase), 15, 0.2) {
+		t.Errorf("fit = mean %v, amplitude %v, peak %v; want 28, 8, 15", mean, amplitude, phase)
+	}
+
+	if _, _, _, ok := FitDiurnalTemperature(hourly[:11]); ok {
+		t.Errorf("fit succeeded with only 10 valid hours")
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+type DailyETResult struct {
+	Year int
+	Day  int
+	Date time.Time
+	// ETref is the daily reference evapotranspiration in mm, the sum of the
+	// station's hourly Evapotranspiration values.
+	ETref float32
+	// Hours is the number of hours with a valid Evapotranspiration value.
+	Hours int
+}
+
+func DailyETref(hourly []HourlyWeatherData) []DailyETResult {
+	days := groupByDay(hourly)
+	results := make([]DailyETResult, 0, len(days))
+	for _, day := range days {
+		first := day[0]
+		result := DailyETResult{
+			Year: first.Year,
+			Day:  first.Day,
+			Date: time.Date(first.Year, 1, first.Day, 0, 0, 0, 0, first.Time.Location()),
+		}
+		for _, rec := range day {
+			if IsMissing(rec.Evapotranspiration) {
+				continue
+			}
+			result.ETref += rec.Evapotranspiration
+			result.Hours++
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// CropWaterRequirement returns ETref × kc for each day, in mm.
+func CropWaterRequirement(dailyETref []DailyETResult, kc float32) []float32 {
+	return CropWaterRequirementCurve(dailyETref, func(int) float32 { return kc })
+}
+
+// CropWaterRequirementCurve is CropWaterRequirement with a crop coefficient
+// that varies by day of year.
+func CropWaterRequirementCurve(dailyETref []DailyETResult, kc func(day int) float32) []float32 {
+	need := make([]float32, len(dailyETref))
+	for i, day := range dailyETref {
+		need[i] = day.ETref * kc(day.Day)
+	}
+	return need
+}
+
+// KcStage sets the crop coefficient from a day of year until the next stage.
+type KcStage struct {
+	FromDay int
+	Kc      float32
+}
+
+// KcCurve builds a stepwise crop coefficient curve for
+// CropWaterRequirementCurve from growth stages sorted by FromDay. Days before
+// the first stage use the first stage's coefficient.
+func KcCurve(stages []KcStage) func(day int) float32 {
+	return func(day int) float32 {
+		if len(stages) == 0 {
+			return 0
+		}
+		kc := stages[0].Kc
+		for _, stage := range stages {
+			if day < stage.FromDay {
+				break
+			}
+			kc = stage.Kc
+		}
+		return kc
+	}
+}
+
+// HargreavesPET estimates daily reference evapotranspiration in mm using the
+// Hargreaves-Samani equation (FAO-56 eq. 52):
+//
+//	ET0 = 0.0023 (Tmean + 17.8) sqrt(Tmax - Tmin) 0.408 Ra
+//
+// with temperatures in °C and Ra the extraterrestrial radiation in MJ/m² at
+// the station's latitude. Days without valid temperatures yield MissingValue,
+// as does every day when station is not in the station table.
+func HargreavesPET(daily []DailyAggregate, station WeatherStation) []float32 {
+	pet := make([]float32, len(daily))
+	info, known := LookupStation(station)
+	for i, day := range daily {
+		if !known || IsMissing(day.MaxAirTemperature) || IsMissing(day.MinAirTemperature) || IsMissing(day.MeanAirTemperature) {
+			pet[i] = MissingValue
+			continue
+		}
+		ra := ExtraterrestrialRadiation(info.Latitude, day.Day)
+		spread := math.Max(0, float64(day.MaxAirTemperature-day.MinAirTemperature))
+		pet[i] = float32(0.0023 * (float64(day.MeanAirTemperature) + 17.8) * math.Sqrt(spread) * 0.408 * ra)
+	}
+	return pet
+}
+
+// ETDeficit returns the daily irrigation requirement in mm, max(ETref × kc -
+// precipitation, 0). dailyPrecip is matched to dailyETref by index; days
+// beyond its end are treated as dry.
+func ETDeficit(dailyETref []DailyETResult, dailyPrecip []float32, kc float32) []float32 {
+	need := CropWaterRequirement(dailyETref, kc)
+	for i := range need {
+		if i < len(dailyPrecip) && !IsMissing(dailyPrecip[i]) {
+			need[i] -= dailyPrecip[i]
+		}
+		if need[i] < 0 {
+			need[i] = 0
+		}
+	}
+	return need
+}
+
+// Constants for OpenWaterEvaporation.
+const (
+	latentHeatVaporization = 2.45      // MJ/kg
+	waterAlbedo            = 0.08      // open water shortwave albedo
+	stefanBoltzmannHourly  = 2.043e-10 // MJ/(K⁴·m²·h)
+	anemometerHeight       = 3.0       // m, AZMET wind sensor height
+)
+
+// atmosphericPressure returns the standard-atmosphere pressure in kPa at an
+// elevation in meters (FAO-56 eq. 7).
+func atmosphericPressure(elevation float64) float64 {
+	return 101.3 * math.Pow((293-0.0065*elevation)/293, 5.26)
+}
+
+// OpenWaterEvaporation estimates hourly evaporation in mm from open water
+// using the Penman combination equation in Shuttleworth's (1993) form:
+//
+//	E = [Δ Rn + γ 6.43 (1 + 0.536 u2) D / 24] / (λ (Δ + γ))
+//
+// Rn is net radiation over water in MJ/m² for the hour: shortwave from
+// SolarRadiation with an albedo of 0.08, less FAO-56 net longwave (eq. 39)
+// whose cloudiness factor uses the clear-sky radiation at the station's
+// coordinates and carries the last daylight value through the night. Water
+// heat storage is neglected. D is the vapor pressure deficit in kPa, u2 the
+// wind speed adjusted from the 3 m AZMET anemometer to 2 m, and γ is derived
+// from the station's elevation. Negative values, which indicate
+// condensation, are reported as zero; hours with missing inputs, and every
+// hour of a station not in the station table, are MissingValue.
+func OpenWaterEvaporation(hourly []HourlyWeatherData, station WeatherStation) []float32 {
+	evap := make([]float32, len(hourly))
+	info, known := LookupStation(station)
+	if !known {
+		for i := range evap {
+			evap[i] = MissingValue
+		}
+		return evap
+	}
+	latitude, longitude, elevation := info.Latitude, info.Longitude, info.Elevation
+
+	gamma := 0.000665 * atmosphericPressure(elevation)
+	windFactor := 4.87 / math.Log(67.8*anemometerHeight-5.42)
+	cloudiness := 0.7
+
+	for i, rec := range hourly {
+		d, ok := rec.vpd()
+		if !ok || IsMissing(rec.AirTemperature) || IsMissing(rec.SolarRadiation) || IsMissing(rec.WindSpeedAverage) {
+			evap[i] = MissingValue
+			continue
+		}
+		t := float64(rec.AirTemperature)
+		es := saturationVaporPressure(rec.AirTemperature)
+		ea := es - float64(d)
+		delta := 4098 * es / math.Pow(t+237.3, 2)
+
+		rs := float64(rec.SolarRadiation)
+		rso := (0.75 + 2e-5*elevation) * hourlyExtraterrestrialRadiation(latitude, longitude, rec.Day, rec.Hour)
+		if rso > 0.1 {
+			cloudiness = math.Max(0.05, math.Min(1, 1.35*math.Min(rs/rso, 1)-0.35))
+		}
+		rnl := stefanBoltzmannHourly * math.Pow(t+273.16, 4) * (0.34 - 0.14*math.Sqrt(math.Max(ea, 0))) * cloudiness
+		rn := (1-waterAlbedo)*rs - rnl
+
+		u2 := float64(rec.WindSpeedAverage) * windFactor
+		e := (delta*rn + gamma*6.43*(1+0.536*u2)*float64(d)/24) / (latentHeatVaporization * (delta + gamma))
+		evap[i] = float32(math.Max(0, e))
+	}
+	return evap
+}
+
+// AridityIndex returns the ratio of annual precipitation to potential
+// evapotranspiration (in the same units) and its UNEP classification:
+// "hyper-arid" below 0.05, "arid" below 0.20, "semi-arid" below 0.50,
+// "dry sub-humid" below 0.65 and "humid" otherwise. A non-positive PET
+// yields NaN and "undefined".
+func AridityIndex(annualPrecip, annualPET float32) (float32, string) {
+	if annualPET <= 0 {
+		return float32(math.NaN()), "undefined"
+	}
+	ratio := annualPrecip / annualPET
+	switch {
+	case ratio < 0.05:
+		return ratio, "hyper-arid"
+	case ratio < 0.20:
+		return ratio, "arid"
+	case ratio < 0.50:
+		return ratio, "semi-arid"
+	case ratio < 0.65:
+		return ratio, "dry sub-humid"
+	default:
+		return ratio, "humid"
+	}
+}
+
+// SoilWaterBalance runs a single-bucket soil water model, in mm, starting
+// from a full bucket. Each day the store gains the day's precipitation, is
+// capped at capacity with the excess lost as runoff or drainage, then loses
+// that day's ETref and is floored at zero. daily and et are matched by index
+// and the store after each day is returned.
+func SoilWaterBalance(daily []DailyAggregate, et []DailyETResult, capacity float32) []float32 {
+	store := capacity
+	balance := make([]float32, 0, len(daily))
+	for i, day := range daily {
+		store += day.Precipitation
+		if store > capacity {
+			store = capacity
+		}
+		if i < len(et) {
+			store -= et[i].ETref
+		}
+		if store < 0 {
+			store = 0
+		}
+		balance = append(balance, store)
+	}
+	return balance
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCropWaterRequirement(t *testing.T) {
+	var daily []DailyETResult
+	for day := 100; day <= 105; day++ {
+		daily = append(daily, DailyETResult{Year: 2020, Day: day, ETref: 8, Hours: 24})
+	}
+
+	for i, need := range CropWaterRequirement(daily, 0.5) {
+		if need != 4 {
+			t.Errorf("day %d: constant Kc requirement = %v, want 4", daily[i].Day, need)
+		}
+	}
+
+	curve := KcCurve([]KcStage{{FromDay: 101, Kc: 0.3}, {FromDay: 103, Kc: 1.2}, {FromDay: 105, Kc: 0.6}})
+	want := []float32{2.4, 2.4, 2.4, 9.6, 9.6, 4.8}
+	for i, need := range CropWaterRequirementCurve(daily, curve) {
+		if !approxEqual(float64(need), float64(want[i]), 1e-5) {
+			t.Errorf("day %d: staged Kc requirement = %v, want %v", daily[i].Day, need, want[i])
+		}
+	}
+
+	if kc := KcCurve(nil)(100); kc != 0 {
+		t.Errorf("empty curve Kc = %v, want 0", kc)
+	}
+}
+
+func TestHargreavesPET(t *testing.T) {
+	// FAO-56 example 8: 20°S on September 3 receives 32.2 MJ/m².
+	if ra := ExtraterrestrialRadiation(-20, 246); !approxEqual(ra, 32.2, 0.05) {
+		t.Errorf("ExtraterrestrialRadiation(-20, 246) = %v, want 32.2", ra)
+	}
+
+	daily := []DailyAggregate{
+		{Day: 172, MaxAirTemperature: 40, MinAirTemperature: 20, MeanAirTemperature: 30},
+		{Day: 173, MaxAirTemperature: MissingValue, MinAirTemperature: 20, MeanAirTemperature: 30},
+	}
+	// Tucson sits at 32.28°N, where Ra on day 172 is 41.41 MJ/m², so
+	// ET0 = 0.0023 × 47.8 × sqrt(20) × 0.408 × 41.41.
+	pet := HargreavesPET(daily, Tucson)
+	if !approxEqual(float64(pet[0]), 8.306, 0.005) {
+		t.Errorf("Tucson solstice PET = %v, want 8.306", pet[0])
+	}
+	if pet[1] != MissingValue {
+		t.Errorf("missing Tmax gave %v, want MissingValue", pet[1])
+	}
+
+	if pet := HargreavesPET(daily, WeatherStation(99)); pet[0] != MissingValue {
+		t.Errorf("unknown station gave %v, want MissingValue", pet[0])
+	}
+}
+
+func TestETDeficit(t *testing.T) {
+	var daily []DailyETResult
+	for day := 1; day <= 4; day++ {
+		daily = append(daily, DailyETResult{Year: 2020, Day: day, ETref: 8, Hours: 24})
+	}
+	precip := []float32{12, 3, MissingValue}
+
+	want := []float32{0, 3, 6, 6} // ETc is 6 mm at Kc 0.75
+	for i, need := range ETDeficit(daily, precip, 0.75) {
+		if !approxEqual(float64(need), float64(want[i]), 1e-5) {
+			t.Errorf("day %d: deficit = %v, want %v", daily[i].Day, need, want[i])
+		}
+	}
+}
+
+func TestOpenWaterEvaporation(t *testing.T) {
+	noon := testRecord(t, 2021, 172, 13)
+	noon.AirTemperature, noon.RelativeHumidity = 35, 20
+	noon.VaporPressureDeficit = MissingValue
+	noon.SolarRadiation, noon.WindSpeedAverage = 3.5, 3
+
+	night := noon
+	night.Hour, night.SolarRadiation = 23, 0
+
+	noTemp := noon
+	noTemp.AirTemperature = MissingValue
+	noTemp.VaporPressureDeficit = 5 // reported, but the temperature is still needed
+
+	// Worked by hand for Tucson's 713 m: Rn = 2.88 MJ/m², γ = 0.0619 kPa/°C,
+	// D = 4.50 kPa and u2 = 2.76 m/s give about 1.18 mm.
+	evap := OpenWaterEvaporation([]HourlyWeatherData{noon, night, noTemp}, Tucson)
+	if !approxEqual(float64(evap[0]), 1.182, 0.005) {
+		t.Errorf("noon evaporation = %v, want 1.182", evap[0])
+	}
+	if evap[1] <= 0 || evap[1] >= evap[0] {
+		t.Errorf("night evaporation = %v, want a smaller positive, wind-driven value", evap[1])
+	}
+	if evap[2] != MissingValue {
+		t.Errorf("missing temperature gave %v, want MissingValue", evap[2])
+	}
+
+	if evap := OpenWaterEvaporation([]HourlyWeatherData{noon}, WeatherStation(99)); evap[0] != MissingValue {
+		t.Errorf("unknown station gave %v, want MissingValue", evap[0])
+	}
+}
+
+func TestAridityIndex(t *testing.T) {
+	tests := []struct {
+		precip, pet float32
+		want        string
+	}{
+		{50, 2000, "hyper-arid"}, // 0.025
+		{200, 2000, "arid"},      // Tucson-like, 0.10
+		{600, 2000, "semi-arid"}, // 0.30
+		{1100, 2000, "dry sub-humid"},
+		{1500, 2000, "humid"},
+		{100, 2000, "arid"}, // exactly 0.05 falls in the next class
+	}
+	for _, tt := range tests {
+		ratio, class := AridityIndex(tt.precip, tt.pet)
+		if class != tt.want || !approxEqual(float64(ratio), float64(tt.precip/tt.pet), 1e-6) {
+			t.Errorf("AridityIndex(%v, %v) = %v %q, want %q", tt.precip, tt.pet, ratio, class, tt.want)
+		}
+	}
+	if ratio, class := AridityIndex(100, 0); class != "undefined" || !math.IsNaN(float64(ratio)) {
+		t.Errorf("zero PET gave %v %q, want NaN \"undefined\"", ratio, class)
+	}
+}
+
+func TestSoilWaterBalance(t *testing.T) {
+	const capacity = 50
+	precip := []float32{0, 0, 30, 0, 0, 0, 0}
+	daily := make([]DailyAggregate, len(precip))
+	et := make([]DailyETResult, len(precip))
+	for i := range precip {
+		daily[i].Precipitation = precip[i]
+		et[i].ETref = 10
+	}
+
+	// Two dry days draw the full bucket down to 30; the storm refills it,
+	// with 10 mm lost over the top, and four dry days then empty it.
+	want := []float32{40, 30, 40, 30, 20, 10, 0}
+	got := SoilWaterBalance(daily, et, capacity)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("day %d: store = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	more := append(daily, DailyAggregate{}, DailyAggregate{Precipitation: 5})
+	if got := SoilWaterBalance(more, et, capacity); got[7] != 0 || got[8] != 5 {
+		t.Errorf("store stayed %v and %v past the ET data, want 0 then 5", got[7], got[8])
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import "math"
+
+// saturationVaporPressure returns the saturation vapor pressure in kPa over
+// water at tempC, using the Tetens equation as given in FAO-56.
+func saturationVaporPressure(tempC float32) float64 {
+	t := float64(tempC)
+	return 0.6108 * math.Exp(17.27*t/(t+237.3))
+}
+
+// ComputeVPD derives the vapor pressure deficit in kPa from AirTemperature and
+// RelativeHumidity.
+func (data HourlyWeatherData) ComputeVPD() float32 {
+	es := saturationVaporPressure(data.AirTemperature)
+	return float32(es * (1 - float64(data.RelativeHumidity)/100))
+}
+
+// vpd returns the reported VaporPressureDeficit, falling back to ComputeVPD
+// when it is missing. ok is false when neither is available.
+func (data HourlyWeatherData) vpd() (float32, bool) {
+	if !IsMissing(data.VaporPressureDeficit) {
+		return data.VaporPressureDeficit, true
+	}
+	if IsMissing(data.AirTemperature) || IsMissing(data.RelativeHumidity) {
+		return 0, false
+	}
+	return data.ComputeVPD(), true
+}
+
+// ComputeDewpoint derives the dewpoint in degrees Celsius from AirTemperature
+// and RelativeHumidity using the Magnus formula.
+func (data HourlyWeatherData) ComputeDewpoint() float32 {
+	const a, b = 17.625, 243.04
+	t := float64(data.AirTemperature)
+	gamma := math.Log(float64(data.RelativeHumidity)/100) + a*t/(b+t)
+	return float32(b * gamma / (a - gamma))
+}
+
+// dewpoint returns the reported DewpointHourAverage, falling back to
+// ComputeDewpoint, or MissingValue when neither is available.
+func (data HourlyWeatherData) dewpoint() float32 {
+	if !IsMissing(data.DewpointHourAverage) {
+		return data.DewpointHourAverage
+	}
+	if IsMissing(data.AirTemperature) || IsMissing(data.RelativeHumidity) {
+		return MissingValue
+	}
+	return data.ComputeDewpoint()
+}
+
+// HumidityComfort labels how humid the air feels based on dewpoint, using the
+// common thresholds of 45, 55 and 65 degrees Fahrenheit. It returns "unknown"
+// when the dewpoint is neither reported nor computable.
+func (data HourlyWeatherData) HumidityComfort() string {
+	dewpoint := data.dewpoint()
+	if IsMissing(dewpoint) {
+		return "unknown"
+	}
+	dewpointF := celsiusToFahrenheit(dewpoint)
+	switch {
+	case dewpointF < 45:
+		return "dry"
+	case dewpointF < 55:
+		return "comfortable"
+	case dewpointF < 65:
+		return "humid"
+	default:
+		return "oppressive"
+	}
+}
+
+type DailyVPD struct {
+	Year int
+	Day  int
+	// Hours is the number of hours with a reported or computed deficit.
+	Hours int
+	Mean  float32
+	Max   float32
+}
+
+// DailySaturationDeficit reports the mean and maximum vapor pressure deficit
+// for each day, filling missing hours with ComputeVPD.
+func DailySaturationDeficit(hourly []HourlyWeatherData) []DailyVPD {
+	daily := make([]DailyVPD, 0)
+	for _, day := range groupByDay(hourly) {
+		agg := DailyVPD{Year: day[0].Year, Day: day[0].Day}
+		var sum float32
+		for _, rec := range day {
+			val, ok := rec.vpd()
+			if !ok {
+				continue
+			}
+			if agg.Hours == 0 || val > agg.Max {
+				agg.Max = val
+			}
+			sum += val
+			agg.Hours++
+		}
+		if agg.Hours > 0 {
+			agg.Mean = sum / float32(agg.Hours)
+		}
+		daily = append(daily, agg)
+	}
+	return daily
+}
+
+// WetBulb estimates the wet-bulb temperature in degrees Celsius from
+// AirTemperature and RelativeHumidity using Stull (2011). The fit is valid
+// for relative humidity between 5% and 99% and temperatures between -20 and
+// 50 °C.
+func (data HourlyWeatherData) WetBulb() float32 {
+	t := float64(data.AirTemperature)
+	rh := float64(data.RelativeHumidity)
+	tw := t*math.Atan(0.151977*math.Sqrt(rh+8.313659)) +
+		math.Atan(t+rh) - math.Atan(rh-1.676331) +
+		0.00391838*math.Pow(rh, 1.5)*math.Atan(0.023101*rh) - 4.686035
+	return float32(tw)
+}
+
+// waterVaporGasConstant is the specific gas constant for water vapor, J/(kg·K).
+const waterVaporGasConstant = 461.5
+
+// AbsoluteHumidity returns the mass of water vapor per volume of air in g/m³.
+// The vapor pressure e is RelativeHumidity times the saturation vapor
+// pressure at AirTemperature, and the ideal gas law gives
+//
+//	AH = 1000 e / (Rv T)
+//
+// with e in Pa, Rv = 461.5 J/(kg·K) and T in kelvin. It returns MissingValue
+// when AirTemperature or RelativeHumidity is missing.
+func (data HourlyWeatherData) AbsoluteHumidity() float32 {
+	if IsMissing(data.AirTemperature) || IsMissing(data.RelativeHumidity) {
+		return MissingValue
+	}
+	e := float64(data.RelativeHumidity) / 100 * saturationVaporPressure(data.AirTemperature) * 1000
+	kelvin := float64(data.AirTemperature) + 273.15
+	return float32(1000 * e / (waterVaporGasConstant * kelvin))
+}
+
+type DailyHumidityRange struct {
+	Year int
+	Day  int
+	// Samples is the number of hours with a valid RelativeHumidity.
+	Samples int
+	Min     float32
+	Max     float32
+	Range   float32
+}
+
+// DailyHumidityRanges reports the spread between each day's highest and
+// lowest relative humidity, skipping missing hours.
+func DailyHumidityRanges(hourly []HourlyWeatherData) []DailyHumidityRange {
+	ranges := make([]DailyHumidityRange, 0)
+	for _, day := range groupByDay(hourly) {
+		r := DailyHumidityRange{Year: day[0].Year, Day: day[0].Day}
+		for _, rec := range day {
+			if IsMissing(rec.RelativeHumidity) {
+				continue
+			}
+			if r.Samples == 0 || rec.RelativeHumidity > r.Max {
+				r.Max = rec.RelativeHumidity
+			}
+			if r.Samples == 0 || rec.RelativeHumidity < r.Min {
+				r.Min = rec.RelativeHumidity
+			}
+			r.Samples++
+		}
+		r.Range = r.Max - r.Min
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// StandardPressure is sea-level atmospheric pressure in kPa.
+const StandardPressure = 101.325
+
+// Enthalpy returns the specific enthalpy of moist air in kJ per kg of dry air
+// at the station's pressure, estimated from its elevation. It returns
+// MissingValue when station is not in the station table.
+func (data HourlyWeatherData) Enthalpy(station WeatherStation) float32 {
+	info, ok := LookupStation(station)
+	if !ok {
+		return MissingValue
+	}
+	return data.EnthalpyAtPressure(atmosphericPressure(info.Elevation))
+}
+
+// EnthalpyAtPressure returns the specific enthalpy of moist air in kJ/kg dry
+// air at a station pressure p in kPa; pass StandardPressure for sea level:
+//
+//	W = 0.622 e / (p - e)
+//	h = 1.006 T + W (2501 + 1.86 T)
+//
+// where e is VaporPressureActual in kPa (derived from RelativeHumidity when
+// missing) and T is AirTemperature in °C. It returns MissingValue when
+// AirTemperature is missing, or when both vapor pressure and humidity are.
+func (data HourlyWeatherData) EnthalpyAtPressure(p float64) float32 {
+	if IsMissing(data.AirTemperature) ||
+		(IsMissing(data.VaporPressureActual) && IsMissing(data.RelativeHumidity)) {
+		return MissingValue
+	}
+	e := float64(data.VaporPressureActual)
+	if IsMissing(data.VaporPressureActual) {
+		e = float64(data.RelativeHumidity) / 100 * saturationVaporPressure(data.AirTemperature)
+	}
+	t := float64(data.AirTemperature)
+	w := 0.622 * e / (p - e)
+	return float32(1.006*t + w*(2501+1.86*t))
+}
+
+type DailyVPDPeak struct {
+	Year int
+	Day  int
+	Hour int
+	// VaporPressureDeficit is the day's highest deficit in kPa.
+	VaporPressureDeficit float32
+}
+
+// DailyVPDPeaks reports the hour of each day's highest vapor pressure
+// deficit, filling missing hours with ComputeVPD. Days with no usable hour
+// are omitted.
+func DailyVPDPeaks(hourly []HourlyWeatherData) []DailyVPDPeak {
+	peaks := make([]DailyVPDPeak, 0)
+	for _, day := range groupByDay(hourly) {
+		found := false
+		var peak DailyVPDPeak
+		for _, rec := range day {
+			val, ok := rec.vpd()
+			if !ok {
+				continue
+			}
+			if !found || val > peak.VaporPressureDeficit {
+				peak = DailyVPDPeak{rec.Year, rec.Day, rec.Hour, val}
+				found = true
+			}
+		}
+		if found {
+			peaks = append(peaks, peak)
+		}
+	}
+	return peaks
+}
@@ -0,0 +1,174 @@
+package main
+
+import "testing"
+
+func TestHumidityComfort(t *testing.T) {
+	tests := []struct {
+		dewpoint float32
+		want     string
+	}{
+		{0, "dry"},
+		{10, "comfortable"},
+		{15, "humid"},
+		{20, "oppressive"},
+	}
+	for _, tt := range tests {
+		rec := HourlyWeatherData{DewpointHourAverage: tt.dewpoint}
+		if got := rec.HumidityComfort(); got != tt.want {
+			t.Errorf("dewpoint %v: HumidityComfort = %q, want %q", tt.dewpoint, got, tt.want)
+		}
+	}
+
+	computed := HourlyWeatherData{AirTemperature: 30, RelativeHumidity: 10, DewpointHourAverage: MissingValue}
+	if got := computed.HumidityComfort(); got != "dry" {
+		t.Errorf("computed dewpoint: HumidityComfort = %q, want \"dry\"", got)
+	}
+
+	for _, rec := range []HourlyWeatherData{
+		{AirTemperature: MissingValue, RelativeHumidity: MissingValue, DewpointHourAverage: MissingValue},
+		{AirTemperature: 30, RelativeHumidity: MissingValue, DewpointHourAverage: MissingValue},
+	} {
+		if got := rec.HumidityComfort(); got != "unknown" {
+			t.Errorf("no dewpoint from %+v: HumidityComfort = %q, want \"unknown\"", rec, got)
+		}
+	}
+}
+
+func TestDailySaturationDeficit(t *testing.T) {
+	var hourly []HourlyWeatherData
+	for _, rh := range []float32{100, 50, 0} {
+		rec := testRecord(t, 2020, 150, len(hourly)+1)
+		rec.AirTemperature = 25
+		rec.RelativeHumidity = rh
+		rec.VaporPressureDeficit = MissingValue
+		hourly = append(hourly, rec)
+	}
+	reported := testRecord(t, 2020, 150, 4)
+	reported.VaporPressureDeficit = 1
+	missing := testRecord(t, 2020, 150, 5)
+	missing.AirTemperature, missing.VaporPressureDeficit = MissingValue, MissingValue
+	hourly = append(hourly, reported, missing)
+
+	daily := DailySaturationDeficit(hourly)
+	if len(daily) != 1 {
+		t.Fatalf("got %d days, want 1", len(daily))
+	}
+	day := daily[0]
+	es := saturationVaporPressure(25) // about 3.17 kPa
+	if day.Hours != 4 {
+		t.Errorf("Hours = %d, want 4", day.Hours)
+	}
+	if !approxEqual(float64(day.Max), es, 1e-4) {
+		t.Errorf("Max = %v, want %v at 0%% humidity", day.Max, es)
+	}
+	if want := (0 + es/2 + es + 1) / 4; !approxEqual(float64(day.Mean), want, 1e-4) {
+		t.Errorf("Mean = %v, want %v", day.Mean, want)
+	}
+}
+
+func TestAbsoluteHumidity(t *testing.T) {
+	tests := []struct {
+		temp, rh float32
+		want     float64 // g/m³, from psychrometric tables
+	}{
+		{20, 50, 8.65},
+		{30, 100, 30.4},
+		{40, 10, 5.1},
+		{0, 80, 3.9},
+	}
+	for _, tt := range tests {
+		rec := HourlyWeatherData{AirTemperature: tt.temp, RelativeHumidity: tt.rh}
+		if got := rec.AbsoluteHumidity(); !approxEqual(float64(got), tt.want, 0.1) {
+			t.Errorf("%v °C, %v%%: AbsoluteHumidity = %v, want %v", tt.temp, tt.rh, got, tt.want)
+		}
+	}
+
+	missing := HourlyWeatherData{AirTemperature: 20, RelativeHumidity: MissingValue}
+	if got := missing.AbsoluteHumidity(); got != MissingValue {
+		t.Errorf("missing humidity: AbsoluteHumidity = %v, want MissingValue", got)
+	}
+}
+
+func TestDailyHumidityRanges(t *testing.T) {
+	var hourly []HourlyWeatherData
+	for hour := 1; hour <= 24; hour++ {
+		rec := testRecord(t, 2020, 190, hour)
+		// Humid before dawn, drying through the afternoon.
+		rec.RelativeHumidity = float32(80 - 3*hour)
+		if hour == 24 {
+			rec.RelativeHumidity = MissingValue
+		}
+		hourly = append(hourly, rec)
+	}
+	allMissing := testRecord(t, 2020, 191, 1)
+	allMissing.RelativeHumidity = MissingValue
+	hourly = append(hourly, allMissing)
+
+	ranges := DailyHumidityRanges(hourly)
+	if len(ranges) != 2 {
+		t.Fatalf("got %d days, want 2", len(ranges))
+	}
+	day := ranges[0]
+	if day.Samples != 23 || day.Max != 77 || day.Min != 11 || day.Range != 66 {
+		t.Errorf("day 190 = %+v, want 23 samples ranging 11 to 77", day)
+	}
+	if ranges[1].Samples != 0 {
+		t.Errorf("day 191 Samples = %d, want 0", ranges[1].Samples)
+	}
+}
+
+func TestEnthalpy(t *testing.T) {
+	rec := HourlyWeatherData{AirTemperature: 30, RelativeHumidity: 50, VaporPressureActual: MissingValue}
+
+	// Psychrometric charts give about 64.2 kJ/kg at sea level.
+	if got := rec.EnthalpyAtPressure(StandardPressure); !approxEqual(float64(got), 64.19, 0.05) {
+		t.Errorf("sea level enthalpy = %v, want 64.19", got)
+	}
+	// Tucson's 713 m puts it near 93.2 kPa, where the same air carries more
+	// water per kilogram of dry air.
+	if got := rec.Enthalpy(Tucson); !approxEqual(float64(got), 67.24, 0.05) {
+		t.Errorf("Tucson enthalpy = %v, want 67.24", got)
+	}
+
+	reported := rec
+	reported.VaporPressureActual = float32(saturationVaporPressure(30) / 2)
+	reported.RelativeHumidity = MissingValue
+	if got := reported.EnthalpyAtPressure(StandardPressure); !approxEqual(float64(got), 64.19, 0.05) {
+		t.Errorf("enthalpy from VaporPressureActual = %v, want 64.19", got)
+	}
+
+	missing := rec
+	missing.RelativeHumidity = MissingValue
+	if got := missing.EnthalpyAtPressure(StandardPressure); got != MissingValue {
+		t.Errorf("missing humidity: got %v, want MissingValue", got)
+	}
+	if got := rec.Enthalpy(WeatherStation(99)); got != MissingValue {
+		t.Errorf("unknown station: got %v, want MissingValue", got)
+	}
+}
+
+func TestDailyVPDPeaks(t *testing.T) {
+	var hourly []HourlyWeatherData
+	for hour := 1; hour <= 24; hour++ {
+		rec := testRecord(t, 2020, 200, hour)
+		rec.AirTemperature, rec.RelativeHumidity = 30, 40
+		// Reported deficit climbs to an afternoon peak at 15:00.
+		rec.VaporPressureDeficit = 6 - float32(abs(hour-15))*0.4
+		if rec.VaporPressureDeficit < 0.5 {
+			rec.VaporPressureDeficit = 0.5
+		}
+		hourly = append(hourly, rec)
+	}
+	hourly[15].VaporPressureDeficit = MissingValue // falls back to ComputeVPD, about 2.5
+	night := testRecord(t, 2020, 201, 3)
+	night.VaporPressureDeficit, night.AirTemperature = MissingValue, MissingValue
+	hourly = append(hourly, night)
+
+	peaks := DailyVPDPeaks(hourly)
+	if len(peaks) != 1 {
+		t.Fatalf("got %d peaks, want 1 (day 201 has no valid deficit)", len(peaks))
+	}
+	if p := peaks[0]; p.Day != 200 || p.Hour != 15 || p.VaporPressureDeficit != 6 {
+		t.Errorf("peak = %+v, want 6 kPa at hour 15 of day 200", p)
+	}
+}
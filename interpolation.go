@@ -0,0 +1,90 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"time"
+)
+
+type NeighborSeries struct {
+	Data []HourlyWeatherData
+	// Distance from the target station, in any consistent unit.
+	Distance float64
+}
+
+// FillFromNeighbors fills missing numeric fields in target by inverse distance
+// weighting (power 2) the same field from neighbors at the same Time. Neighbors
+// with a missing value at that time are skipped and a field stays missing when
+// no neighbor has it. A neighbor at zero distance supplies its value directly.
+// WindDirectionVector is averaged as weighted unit vectors, so neighbors at 350°
+// and 10° fill 0° rather than 180°. WindDirectionStdDev is left missing: the
+// spread reflects turbulence at a single site and does not carry over from
+// other stations. The input slice is not modified.
+func FillFromNeighbors(target []HourlyWeatherData, neighbors []NeighborSeries) []HourlyWeatherData {
+	indexed := make([]map[time.Time]HourlyWeatherData, len(neighbors))
+	for i, n := range neighbors {
+		indexed[i] = make(map[time.Time]HourlyWeatherData, len(n.Data))
+		for _, rec := range n.Data {
+			indexed[i][rec.Time.UTC()] = rec
+		}
+	}
+
+	recType := reflect.TypeOf(HourlyWeatherData{})
+	direction, _ := recType.FieldByName("WindDirectionVector")
+	spread, _ := recType.FieldByName("WindDirectionStdDev")
+
+	filled := make([]HourlyWeatherData, len(target))
+	for r, rec := range target {
+		out := reflect.ValueOf(&rec).Elem()
+		for f := 0; f < out.NumField(); f++ {
+			field := out.Field(f)
+			if field.Kind() != reflect.Float32 || !IsMissing(float32(field.Float())) || f == spread.Index[0] {
+				continue
+			}
+			circular := f == direction.Index[0]
+			var weighted, east, north, weights float64
+			for i, n := range neighbors {
+				nrec, ok := indexed[i][rec.Time.UTC()]
+				if !ok {
+					continue
+				}
+				val := reflect.ValueOf(nrec).Field(f).Float()
+				if IsMissing(float32(val)) {
+					continue
+				}
+				w := 1.0
+				if n.Distance == 0 {
+					// A co-located neighbor replaces anything weighted so far.
+					weighted, east, north, weights = 0, 0, 0, 0
+				} else {
+					w = 1 / (n.Distance * n.Distance)
+				}
+				if circular {
+					rad := val * math.Pi / 180
+					east += w * math.Sin(rad)
+					north += w * math.Cos(rad)
+				} else {
+					weighted += w * val
+				}
+				weights += w
+				if n.Distance == 0 {
+					break
+				}
+			}
+			if weights == 0 {
+				continue
+			}
+			if circular {
+				deg := math.Atan2(east, north) * 180 / math.Pi
+				if deg < 0 {
+					deg += 360
+				}
+				field.SetFloat(deg)
+			} else {
+				field.SetFloat(weighted / weights)
+			}
+		}
+		filled[r] = rec
+	}
+	return filled
+}
@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestFillFromNeighbors(t *testing.T) {
+	target := testRecord(t, 2020, 100, 12)
+	target.AirTemperature = MissingValue
+	target.RelativeHumidity = 40
+	target.SolarRadiation = MissingValue
+
+	near := target
+	near.AirTemperature, near.RelativeHumidity, near.SolarRadiation = 20, 10, MissingValue
+	far := target
+	far.AirTemperature, far.RelativeHumidity, far.SolarRadiation = 30, 90, MissingValue
+
+	neighbors := []NeighborSeries{
+		{Data: []HourlyWeatherData{near}, Distance: 1},
+		{Data: []HourlyWeatherData{far}, Distance: 2},
+	}
+	filled := FillFromNeighbors([]HourlyWeatherData{target}, neighbors)
+
+	// Weights 1 and 1/4: (20 + 30/4) / 1.25 = 22.
+	if got := filled[0].AirTemperature; !approxEqual(float64(got), 22, 1e-4) {
+		t.Errorf("AirTemperature = %v, want 22", got)
+	}
+	if got := filled[0].RelativeHumidity; got != 40 {
+		t.Errorf("present RelativeHumidity changed to %v", got)
+	}
+	if got := filled[0].SolarRadiation; !IsMissing(got) {
+		t.Errorf("SolarRadiation = %v, want missing when no neighbor has it", got)
+	}
+	if !IsMissing(target.AirTemperature) {
+		t.Errorf("input record was modified")
+	}
+}
+
+func TestFillFromNeighborsWindDirection(t *testing.T) {
+	target := testRecord(t, 2020, 100, 12)
+	target.WindDirectionVector, target.WindDirectionStdDev = MissingValue, MissingValue
+
+	west := target
+	west.WindDirectionVector, west.WindDirectionStdDev = 350, 20
+	east := target
+	east.WindDirectionVector, east.WindDirectionStdDev = 10, 40
+
+	filled := FillFromNeighbors([]HourlyWeatherData{target}, []NeighborSeries{
+		{Data: []HourlyWeatherData{west}, Distance: 1},
+		{Data: []HourlyWeatherData{east}, Distance: 1},
+	})
+	got := float64(filled[0].WindDirectionVector)
+	if !approxEqual(got, 0, 1e-3) && !approxEqual(got, 360, 1e-3) {
+		t.Errorf("WindDirectionVector = %v, want north, not the linear 180", got)
+	}
+	if !IsMissing(filled[0].WindDirectionStdDev) {
+		t.Errorf("WindDirectionStdDev = %v, want it left missing", filled[0].WindDirectionStdDev)
+	}
+
+	// A closer neighbor pulls the direction towards its own: weights 1 and
+	// 1/4 put the mean just west of north.
+	filled = FillFromNeighbors([]HourlyWeatherData{target}, []NeighborSeries{
+		{Data: []HourlyWeatherData{west}, Distance: 1},
+		{Data: []HourlyWeatherData{east}, Distance: 2},
+	})
+	if got := filled[0].WindDirectionVector; got < 350 || got > 356 {
+		t.Errorf("weighted WindDirectionVector = %v, want between 350 and 356", got)
+	}
+
+	filled = FillFromNeighbors([]HourlyWeatherData{target}, []NeighborSeries{
+		{Data: []HourlyWeatherData{west}, Distance: 1},
+		{Data: []HourlyWeatherData{east}, Distance: 0},
+	})
+	if got := filled[0].WindDirectionVector; !approxEqual(float64(got), 10, 1e-3) {
+		t.Errorf("co-located WindDirectionVector = %v, want 10", got)
+	}
+}
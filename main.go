@@ -1,32 +1,64 @@
 package main
 
 import (
+	"bytes"
 	"encoding/csv"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
-	"net/http"
+	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
 func main() {
 
-	current := time.Now()
+	client := NewClient()
 
-	var year, station int
-	flag.IntVar(&year, "y", current.Year(), "the year to fetch data between 2003 and current")
-	flag.IntVar(&station, "s", int(PhoenixGreenway), "the weather station to fetch data for")
-	flag.Parse()
+	if len(os.Args) > 1 && os.Args[1] == "archive" {
+		if err := runArchive(client, os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := runFetch(client, os.Args[1:], os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runFetch implements the default command, `azmet -s <station> -y <year> -tz
+// <zone>`, printing a year of hourly records with their times shown in the
+// chosen IANA timezone.
+func runFetch(client *Client, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("azmet", flag.ContinueOnError)
+	year := flags.Int("y", client.now().Year(), "the year to fetch data between 2003 and current")
+	station := flags.Int("s", int(PhoenixGreenway), "the weather station to fetch data for")
+	tz := flags.String("tz", "America/Phoenix", "the IANA timezone to display times in")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", *tz, err)
+	}
 
-	data, err := DownloadHourlyData(WeatherStation(station), year)
+	data, err := client.DownloadHourlyData(WeatherStation(*station), *year)
 	if err != nil {
-		log.Fatal("Error retrieving weather data.")
+		return fmt.Errorf("error retrieving weather data: %w", err)
+	}
+
+	for i := range data {
+		data[i].Time = data[i].Time.In(loc)
 	}
 
-	fmt.Println(data)
+	_, err = fmt.Fprintln(out, data)
+	return err
 }
 
 type HourlyWeatherData struct {
@@ -51,6 +83,14 @@ type HourlyWeatherData struct {
 	Time                 time.Time
 }
 
+// MissingValue is the sentinel AZMET writes in place of an unavailable
+// observation.
+const MissingValue float32 = 999
+
+func IsMissing(val float32) bool {
+	return val != val || val >= MissingValue
+}
+
 type WeatherStation int
 
 const (
@@ -84,36 +124,47 @@ const (
 	YumaValley      WeatherStation = 2
 )
 
-func generateUrl(station WeatherStation, year int) string {
-	urlFormat := "https://cals.arizona.edu/azmet/data/%d%srh.txt"
+func generateFilename(station WeatherStation, year int) string {
 	yearStr := strconv.Itoa(year)
-	return fmt.Sprintf(urlFormat, station, yearStr[len(yearStr)-2:])
+	return fmt.Sprintf("%d%srh.txt", station, yearStr[len(yearStr)-2:])
 }
 
-func DownloadHourlyData(station WeatherStation, year int) ([]HourlyWeatherData, error) {
-
-	if year < 2003 || year > 2099 {
-		return []HourlyWeatherData{}, fmt.Errorf("invalid year to fetch Phoenix weather data: %d", year)
-	}
+func generateUrl(station WeatherStation, year int) string {
+	return "https://cals.arizona.edu/azmet/data/" + generateFilename(station, year)
+}
 
-	url := generateUrl(station, year)
+func DownloadHourlyData(station WeatherStation, year int) ([]HourlyWeatherData, error) {
+	return NewClient().DownloadHourlyData(station, year)
+}
 
-	client := &http.Client{
-		Timeout: time.Second * 10,
-	}
-	response, err := client.Get(url)
+type ParseOptions struct {
+	// Comma is the field delimiter. It defaults to ','.
+	Comma rune
+	// NormalizeNumbers accepts locale-formatted numbers such as "1.234,5" or
+	// "1,234.5" by stripping thousands separators and converting a decimal
+	// comma to a point before parsing.
+	NormalizeNumbers bool
+}
 
-	if err != nil {
-		return []HourlyWeatherData{}, err
-	}
+func DownloadRawData(station WeatherStation, year int) ([]byte, error) {
+	return NewClient().DownloadRawData(station, year)
+}
 
-	return ReadHourlyData(response.Body)
+func ParseHourlyData(raw []byte) ([]HourlyWeatherData, error) {
+	return ReadHourlyData(io.NopCloser(bytes.NewReader(raw)))
 }
 
 func ReadHourlyData(reader io.ReadCloser) ([]HourlyWeatherData, error) {
+	return ReadHourlyDataWithOptions(reader, ParseOptions{})
+}
+
+func ReadHourlyDataWithOptions(reader io.ReadCloser, opts ParseOptions) ([]HourlyWeatherData, error) {
 	defer reader.Close()
 
 	r := csv.NewReader(reader)
+	if opts.Comma != 0 {
+		r.Comma = opts.Comma
+	}
 	data := make([]HourlyWeatherData, 0)
 	for {
 		record, err := r.Read()
@@ -123,7 +174,7 @@ func ReadHourlyData(reader io.ReadCloser) ([]HourlyWeatherData, error) {
 		if err != nil {
 			return []HourlyWeatherData{}, err
 		}
-		rec, err := parseHourlyWeatherData(record)
+		rec, err := parseHourlyWeatherData(record, opts)
 
 		if err != nil {
 			return []HourlyWeatherData{}, err
@@ -139,17 +190,76 @@ func ReadHourlyData(reader io.ReadCloser) ([]HourlyWeatherData, error) {
 	return data, nil
 }
 
+type ParseWarning struct {
+	Line   int
+	Reason string
+}
+
+// ReadHourlyDataLenient parses like ReadHourlyDataWithOptions but skips rows it
+// cannot parse, reporting each as a ParseWarning instead of failing. The error
+// is only set when the input itself cannot be read.
+func ReadHourlyDataLenient(reader io.ReadCloser, opts ParseOptions) ([]HourlyWeatherData, []ParseWarning, error) {
+	defer reader.Close()
+
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	if opts.Comma != 0 {
+		r.Comma = opts.Comma
+	}
+	data := make([]HourlyWeatherData, 0)
+	warnings := make([]ParseWarning, 0)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return []HourlyWeatherData{}, []ParseWarning{}, err
+		}
+		line, _ := r.FieldPos(0)
+		rec, err := parseHourlyWeatherData(record, opts)
+		if err != nil {
+			warnings = append(warnings, ParseWarning{line, err.Error()})
+			continue
+		}
+		date, err := WeatherDataDate(rec)
+		if err != nil {
+			warnings = append(warnings, ParseWarning{line, err.Error()})
+			continue
+		}
+		rec.Time = date
+		data = append(data, rec)
+	}
+
+	return data, warnings, nil
+}
+
+func ReadHourlyDataFS(fsys fs.FS, name string) ([]HourlyWeatherData, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return []HourlyWeatherData{}, err
+	}
+	return ReadHourlyData(file)
+}
+
 func WeatherDataDate(data HourlyWeatherData) (time.Time, error) {
 	tz, err := time.LoadLocation("America/Phoenix")
 	if err != nil {
 		return time.Time{}, fmt.Errorf("unable to resolve timezone")
 	}
+	if data.Hour < 0 || data.Hour > 24 {
+		return time.Time{}, fmt.Errorf("invalid hour for weather data: %d", data.Hour)
+	}
+	daysInYear := time.Date(data.Year, time.December, 31, 0, 0, 0, 0, tz).YearDay()
+	if data.Day < 1 || data.Day > daysInYear {
+		return time.Time{}, fmt.Errorf("invalid day of year for weather data in %d: %d", data.Year, data.Day)
+	}
 	firstOfYear := time.Date(data.Year, 1, 1, data.Hour, 0, 0, 0, tz)
 	val := firstOfYear.Add(time.Hour * 24 * time.Duration(data.Day-1))
 	return val, nil
 }
 
-func parseHourlyWeatherData(record []string) (HourlyWeatherData, error) {
+func parseHourlyWeatherData(record []string, opts ParseOptions) (HourlyWeatherData, error) {
 	if len(record) != 18 {
 		return HourlyWeatherData{}, fmt.Errorf("invalid field list length for hourly weather data, expecting 18 fields received %v", len(record))
 	}
@@ -163,15 +273,19 @@ func parseHourlyWeatherData(record []string) (HourlyWeatherData, error) {
 		if !field.CanSet() {
 			return HourlyWeatherData{}, fmt.Errorf("field %s cannot be set", s.Type().Field(i).Name)
 		}
+		value := record[i]
+		if opts.NormalizeNumbers {
+			value = normalizeNumber(value)
+		}
 		switch field.Type().Kind() {
 		case reflect.Int:
-			val, err := strconv.Atoi(record[i])
+			val, err := strconv.Atoi(value)
 			if err != nil {
 				return HourlyWeatherData{}, fmt.Errorf("unable to parse int type for value: %s", record[i])
 			}
 			field.Set(reflect.ValueOf(val))
 		case reflect.Float32:
-			val, err := strconv.ParseFloat(record[i], 32)
+			val, err := strconv.ParseFloat(value, 32)
 			if err != nil {
 				return HourlyWeatherData{}, fmt.Errorf("unable to parse float32 type for value: %s", record[i])
 			}
@@ -183,3 +297,32 @@ func parseHourlyWeatherData(record []string) (HourlyWeatherData, error) {
 
 	return data, nil
 }
+
+// normalizeNumber rewrites a locale-formatted number into the form strconv
+// expects. When both separators appear the last one is the decimal mark. A
+// lone comma is a decimal mark unless it is followed by exactly three digits
+// after a non-zero integer part, as in "1,234"; repeated commas are always
+// thousands separators.
+func normalizeNumber(value string) string {
+	value = strings.TrimSpace(value)
+	comma := strings.LastIndex(value, ",")
+	point := strings.LastIndex(value, ".")
+
+	switch {
+	case comma < 0:
+		return value
+	case point >= 0 && point > comma:
+		return strings.ReplaceAll(value, ",", "")
+	case point >= 0:
+		value = strings.ReplaceAll(value, ".", "")
+		return strings.Replace(value, ",", ".", 1)
+	case strings.Count(value, ",") > 1:
+		return strings.ReplaceAll(value, ",", "")
+	}
+
+	integer := strings.TrimLeft(value[:comma], "+-")
+	if len(value)-comma-1 == 3 && integer != "0" && integer != "" {
+		return strings.Replace(value, ",", "", 1)
+	}
+	return strings.Replace(value, ",", ".", 1)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRecord returns a record for the given year, day and hour with its Time
+// filled in and every observation zero.
+func testRecord(t *testing.T, year, day, hour int) HourlyWeatherData {
+	t.Helper()
+	rec := HourlyWeatherData{Year: year, Day: day, Hour: hour}
+	date, err := WeatherDataDate(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Time = date
+	return rec
+}
+
+// sameRecord compares records field by field, treating Times as equal when
+// they name the same instant whatever their Location.
+func sameRecord(a, b HourlyWeatherData) bool {
+	if !a.Time.Equal(b.Time) {
+		return false
+	}
+	a.Time = b.Time
+	return a == b
+}
+
+func approxEqual(a, b, tolerance float64) bool {
+	d := a - b
+	return d <= tolerance && d >= -tolerance
+}
+
+func TestRunFetchTimezone(t *testing.T) {
+	files := map[string]string{"1220rh.txt": hourlyCSV(2020, 1)}
+	client, _ := newStubClient(time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC), files)
+
+	var out bytes.Buffer
+	if err := runFetch(client, []string{"-y", "2020"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "2020-01-01 01:00:00 -0700 MST") {
+		t.Errorf("default output is not in Arizona time: %.200s", out.String())
+	}
+
+	out.Reset()
+	if err := runFetch(client, []string{"-y", "2020", "-tz", "America/New_York"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	// The same instant, displayed two hours later on the east coast.
+	if !strings.Contains(out.String(), "2020-01-01 03:00:00 -0500 EST") {
+		t.Errorf("output is not in New York time: %.200s", out.String())
+	}
+
+	err := runFetch(client, []string{"-y", "2020", "-tz", "Mars/Olympus_Mons"}, &out)
+	if err == nil || !strings.Contains(err.Error(), "invalid timezone") {
+		t.Errorf("bad timezone: err = %v, want an invalid timezone error", err)
+	}
+}
+
+//go:embed testdata/1220rh.txt
+var testdataFS embed.FS
+
+func TestReadHourlyDataFS(t *testing.T) {
+	data, err := ReadHourlyDataFS(testdataFS, "testdata/1220rh.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 24 {
+		t.Fatalf("got %d records, want 24", len(data))
+	}
+	last := data[23]
+	if last.Hour != 24 || last.AirTemperature != 15.2 || last.RelativeHumidity != 21 {
+		t.Errorf("last record = %+v", last)
+	}
+	if want := time.Date(2020, time.January, 2, 0, 0, 0, 0, time.FixedZone("MST", -7*60*60)); !last.Time.Equal(want) {
+		t.Errorf("hour 24 Time = %v, want %v", last.Time, want)
+	}
+
+	if _, err := ReadHourlyDataFS(testdataFS, "testdata/missing.txt"); err == nil {
+		t.Errorf("expected an error opening a file absent from the FS")
+	}
+}
+
+func TestReadHourlyDataLocaleNumbers(t *testing.T) {
+	// A semicolon-delimited export from a European spreadsheet.
+	fixture := "2020;1;1;12,5;30;1,25;0;0;15;15;1;1;180;10;2;0,1;1;-3,5\n" +
+		"2020;1;2;1.012,5;30;1;0;0;15;15;1;1;180;10;2;0,1;1;5\n"
+	opts := ParseOptions{Comma: ';', NormalizeNumbers: true}
+	data, err := ReadHourlyDataWithOptions(io.NopCloser(strings.NewReader(fixture)), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0].AirTemperature != 12.5 || data[0].VaporPressureDeficit != 1.25 || data[0].DewpointHourAverage != -3.5 {
+		t.Errorf("decimal commas parsed as %+v", data[0])
+	}
+	if data[1].AirTemperature != 1012.5 {
+		t.Errorf("thousands separator parsed as %v, want 1012.5", data[1].AirTemperature)
+	}
+
+	opts.NormalizeNumbers = false
+	if _, err := ReadHourlyDataWithOptions(io.NopCloser(strings.NewReader(fixture)), opts); err == nil {
+		t.Errorf("expected decimal commas to fail without NormalizeNumbers")
+	}
+
+	for in, want := range map[string]string{
+		"1,234":     "1234",
+		"0,125":     "0.125",
+		"1,5":       "1.5",
+		"1,234.5":   "1234.5",
+		"1.234,5":   "1234.5",
+		"1,234,567": "1234567",
+		"-2,25":     "-2.25",
+		"42":        "42",
+	} {
+		if got := normalizeNumber(in); got != want {
+			t.Errorf("normalizeNumber(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestReadHourlyDataLenient(t *testing.T) {
+	fixture := "2020,1,1,12.5,30,1,0,0,15,15,1,1,180,10,2,0.1,1,5\n" +
+		"2020,1,2,abc,30,1,0,0,15,15,1,1,180,10,2,0.1,1,5\n" + // bad number
+		"2020,1,3,12.5,30\n" + // truncated row
+		"2020,1,25,12.5,30,1,0,0,15,15,1,1,180,10,2,0.1,1,5\n" + // bad hour
+		"2020,1,4,13,31,1,0,0,15,15,1,1,180,10,2,0.1,1,5\n"
+	data, warnings, err := ReadHourlyDataLenient(io.NopCloser(strings.NewReader(fixture)), ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data[0].Hour != 1 || data[1].Hour != 4 {
+		t.Errorf("kept %+v, want the rows for hours 1 and 4", data)
+	}
+	if len(warnings) != 3 {
+		t.Fatalf("got %d warnings, want 3: %v", len(warnings), warnings)
+	}
+	for i, line := range []int{2, 3, 4} {
+		if warnings[i].Line != line || warnings[i].Reason == "" {
+			t.Errorf("warning %d = %+v, want a reason for line %d", i, warnings[i], line)
+		}
+	}
+
+	if _, err := ReadHourlyData(io.NopCloser(strings.NewReader(fixture))); err == nil {
+		t.Errorf("expected the strict parser to fail on the same input")
+	}
+}
+
+func TestWeatherDataDateRange(t *testing.T) {
+	tests := []struct {
+		year, day, hour int
+		valid           bool
+	}{
+		{2020, 1, 0, true},
+		{2020, 366, 24, true}, // leap year, rolls over to January 1
+		{2021, 365, 24, true},
+		{2021, 366, 1, false},
+		{2020, 0, 1, false},
+		{2020, 10, 25, false},
+		{2020, 10, -1, false},
+	}
+	for _, tt := range tests {
+		date, err := WeatherDataDate(HourlyWeatherData{Year: tt.year, Day: tt.day, Hour: tt.hour})
+		if (err == nil) != tt.valid {
+			t.Errorf("%d day %d hour %d: err = %v, want valid %v", tt.year, tt.day, tt.hour, err, tt.valid)
+		}
+		if err != nil && !date.IsZero() {
+			t.Errorf("%d day %d hour %d: got %v alongside an error", tt.year, tt.day, tt.hour, date)
+		}
+	}
+
+	date, _ := WeatherDataDate(HourlyWeatherData{Year: 2020, Day: 366, Hour: 24})
+	if date.Year() != 2021 || date.YearDay() != 1 || date.Hour() != 0 {
+		t.Errorf("day 366 hour 24 = %v, want midnight starting 2021", date)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CSVOptions struct {
+	// Comma is the field delimiter. It defaults to ','.
+	Comma rune
+	// UseCRLF terminates each line with \r\n instead of \n.
+	UseCRLF bool
+	// QuoteAll wraps every field in double quotes rather than only the fields
+	// that require it.
+	QuoteAll bool
+	// Timestamp prepends each row with the record's Time in ISO 8601 form,
+	// in America/Phoenix with its UTC offset. Output with a timestamp column
+	// can no longer be read back by ReadHourlyData.
+	Timestamp bool
+}
+
+// WriteHourlyData writes records as CSV in the same field order AZMET
+// publishes, so unless Timestamp is set the output can be read back with
+// ReadHourlyData.
+func WriteHourlyData(w io.Writer, data []HourlyWeatherData, opts CSVOptions) error {
+	if opts.Comma == 0 {
+		opts.Comma = ','
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.Comma
+	cw.UseCRLF = opts.UseCRLF
+
+	var tz *time.Location
+	if opts.Timestamp {
+		var err error
+		tz, err = time.LoadLocation("America/Phoenix")
+		if err != nil {
+			return fmt.Errorf("unable to resolve timezone")
+		}
+	}
+
+	for _, rec := range data {
+		record, err := formatHourlyWeatherData(rec)
+		if err != nil {
+			return err
+		}
+		if opts.Timestamp {
+			record = append([]string{rec.Time.In(tz).Format(time.RFC3339)}, record...)
+		}
+		if opts.QuoteAll {
+			err = writeQuoted(w, record, opts)
+		} else {
+			err = cw.Write(record)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeQuoted(w io.Writer, record []string, opts CSVOptions) error {
+	quoted := make([]string, len(record))
+	for i, field := range record {
+		quoted[i] = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+	}
+	line := strings.Join(quoted, string(opts.Comma))
+	if opts.UseCRLF {
+		line += "\r\n"
+	} else {
+		line += "\n"
+	}
+	_, err := io.WriteString(w, line)
+	return err
+}
+
+func formatHourlyWeatherData(data HourlyWeatherData) ([]string, error) {
+	s := reflect.ValueOf(data)
+	record := make([]string, 18)
+
+	for i := 0; i < 18; i++ {
+		field := s.Field(i)
+		switch field.Type().Kind() {
+		case reflect.Int:
+			record[i] = strconv.Itoa(int(field.Int()))
+		case reflect.Float32:
+			record[i] = strconv.FormatFloat(field.Float(), 'f', -1, 32)
+		default:
+			return []string{}, fmt.Errorf("unable to format type for field: %s", field.Type().String())
+		}
+	}
+
+	return record, nil
+}
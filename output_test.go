@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func sampleRecords(t *testing.T) []HourlyWeatherData {
+	t.Helper()
+	data, err := ReadHourlyData(io.NopCloser(strings.NewReader(
+		"2020,1,1,12.5,30,1,0,0,15,15,1,1,180,10,2,0.1,1,5\n" +
+			"2020,1,2,11.75,35,0.8,0,0.2,15,15,1,1,181,10,2,0.05,1,4.5\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWriteHourlyDataRoundTrip(t *testing.T) {
+	data := sampleRecords(t)
+	var buf bytes.Buffer
+	if err := WriteHourlyData(&buf, data, CSVOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadHourlyData(io.NopCloser(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != len(data) {
+		t.Fatalf("read back %d records, want %d", len(back), len(data))
+	}
+	got, want := back[1], data[1]
+	if !got.Time.Equal(want.Time) {
+		t.Errorf("time = %v, want %v", got.Time, want.Time)
+	}
+	got.Time = want.Time
+	if got != want {
+		t.Errorf("round trip changed record: %v vs %v", got, want)
+	}
+}
+
+func TestWriteHourlyDataCRLF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHourlyData(&buf, sampleRecords(t), CSVOptions{UseCRLF: true}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\r\n")
+	if len(lines) != 3 || lines[2] != "" {
+		t.Fatalf("expected two CRLF-terminated lines, got %q", buf.String())
+	}
+	if lines[0] != "2020,1,1,12.5,30,1,0,0,15,15,1,1,180,10,2,0.1,1,5" {
+		t.Errorf("unexpected first line %q", lines[0])
+	}
+}
+
+func TestWriteHourlyDataQuoteAll(t *testing.T) {
+	var buf bytes.Buffer
+	opts := CSVOptions{Comma: ';', QuoteAll: true}
+	if err := WriteHourlyData(&buf, sampleRecords(t)[:1], opts); err != nil {
+		t.Fatal(err)
+	}
+	want := `"2020";"1";"1";"12.5";"30";"1";"0";"0";"15";"15";"1";"1";"180";"10";"2";"0.1";"1";"5"` + "\n"
+	if buf.String() != want {
+		t.Errorf("QuoteAll output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteHourlyDataTimestamp(t *testing.T) {
+	data := sampleRecords(t)
+	// A UTC Time is still written in Arizona time.
+	data[1].Time = data[1].Time.UTC()
+
+	var buf bytes.Buffer
+	if err := WriteHourlyData(&buf, data, CSVOptions{Timestamp: true}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, want := range []string{"2020-01-01T01:00:00-07:00,2020,1,1,", "2020-01-01T02:00:00-07:00,2020,1,2,"} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PollCurrent downloads the current year's data for station immediately and
+// then every interval, sending only records whose Time has not been seen
+// before. Fetch errors are sent on the error channel and polling continues;
+// the channel buffers one error, and errors arriving while it is full are
+// dropped rather than stalling the records, so callers need not drain it.
+// Both channels are closed once ctx is cancelled. An interval of zero or less
+// does not poll at all: the error channel carries a single error and both
+// channels are closed straight away.
+func (c *Client) PollCurrent(ctx context.Context, station WeatherStation, interval time.Duration) (<-chan HourlyWeatherData, <-chan error) {
+	records := make(chan HourlyWeatherData)
+	errs := make(chan error, 1)
+
+	if interval <= 0 {
+		errs <- fmt.Errorf("invalid polling interval: %v", interval)
+		close(records)
+		close(errs)
+		return records, errs
+	}
+
+	go func() {
+		defer close(records)
+		defer close(errs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		seen := make(map[time.Time]bool)
+		for {
+			data, err := c.DownloadHourlyData(station, c.now().Year())
+			if err != nil {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+			for _, rec := range data {
+				key := rec.Time.UTC()
+				if seen[key] {
+					continue
+				}
+				select {
+				case records <- rec:
+					seen[key] = true
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return records, errs
+}
+
+func PollCurrent(ctx context.Context, station WeatherStation, interval time.Duration) (<-chan HourlyWeatherData, <-chan error) {
+	return NewClient().PollCurrent(ctx, station, interval)
+}
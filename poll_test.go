@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPollCurrent(t *testing.T) {
+	files := map[string]string{"1221rh.txt": hourlyCSV(2021, 1)}
+	client, transport := newStubClient(time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC), files)
+	setFile := func(body string, ok bool) {
+		transport.mu.Lock()
+		defer transport.mu.Unlock()
+		if ok {
+			transport.files["1221rh.txt"] = body
+		} else {
+			delete(transport.files, "1221rh.txt")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	records, errs := client.PollCurrent(ctx, PhoenixGreenway, 10*time.Millisecond)
+
+	receive := func(n, wantDay int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			select {
+			case rec := <-records:
+				if rec.Day != wantDay {
+					t.Fatalf("record %d came from day %d, want only new records from day %d", i, rec.Day, wantDay)
+				}
+			case <-ctx.Done():
+				t.Fatalf("timed out after %d of %d records", i, n)
+			}
+		}
+	}
+
+	receive(24, 1)
+	setFile(hourlyCSV(2021, 2), true)
+	receive(24, 2)
+
+	setFile("", false)
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("err = %v, want ErrNotFound", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the fetch error")
+	}
+
+	cancel()
+	for range records {
+	}
+	if _, ok := <-errs; ok {
+		// A buffered error may remain; the channel must still close.
+		if _, ok := <-errs; ok {
+			t.Errorf("error channel not closed after cancellation")
+		}
+	}
+}
+
+func TestPollCurrentInvalidInterval(t *testing.T) {
+	client, transport := newStubClient(time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC), map[string]string{})
+	for _, interval := range []time.Duration{0, -time.Second} {
+		records, errs := client.PollCurrent(context.Background(), Tucson, interval)
+		if err, ok := <-errs; !ok || err == nil {
+			t.Errorf("interval %v: no error reported", interval)
+		}
+		if _, ok := <-errs; ok {
+			t.Errorf("interval %v: error channel not closed", interval)
+		}
+		if _, ok := <-records; ok {
+			t.Errorf("interval %v: records channel not closed", interval)
+		}
+	}
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.requests) != 0 {
+		t.Errorf("made %d requests with an invalid interval, want none", len(transport.requests))
+	}
+}
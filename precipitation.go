@@ -0,0 +1,94 @@
+package main
+
+import "time"
+
+// WaterYear returns the October 1 - September 30 water year a record falls in,
+// labeled by the calendar year in which it ends.
+func WaterYear(rec HourlyWeatherData) int {
+	date := time.Date(rec.Year, 1, rec.Day, 0, 0, 0, 0, time.UTC)
+	if date.Month() >= time.October {
+		return rec.Year + 1
+	}
+	return rec.Year
+}
+
+// WaterYearPrecip totals precipitation by water year. Missing observations
+// count as zero, so a water year with data but no valid observation is still
+// reported; see WaterYearCoverage for how complete each total is.
+func WaterYearPrecip(data []HourlyWeatherData) map[int]float32 {
+	totals := make(map[int]float32)
+	for _, rec := range data {
+		wy := WaterYear(rec)
+		total := totals[wy]
+		if !IsMissing(rec.Precipitation) {
+			total += rec.Precipitation
+		}
+		totals[wy] = total
+	}
+	return totals
+}
+
+// WaterYearCoverage reports, per water year, the fraction of the year's hours
+// that have a valid precipitation observation. Every water year present in
+// data is reported, with zero coverage when none of its hours are valid.
+func WaterYearCoverage(data []HourlyWeatherData) map[int]float64 {
+	counts := make(map[int]int)
+	for _, rec := range data {
+		wy := WaterYear(rec)
+		n := counts[wy]
+		if !IsMissing(rec.Precipitation) {
+			n++
+		}
+		counts[wy] = n
+	}
+	coverage := make(map[int]float64)
+	for wy, n := range counts {
+		start := time.Date(wy-1, time.October, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(wy, time.October, 1, 0, 0, 0, 0, time.UTC)
+		coverage[wy] = float64(n) / end.Sub(start).Hours()
+	}
+	return coverage
+}
+
+// PrecipIntensityCategory labels the hour's rainfall rate using the American
+// Meteorological Society thresholds: "light" below 2.5 mm/h, "moderate" below
+// 7.6 mm/h and "heavy" at or above. Dry hours are "none" and missing
+// observations "missing".
+func (data HourlyWeatherData) PrecipIntensityCategory() string {
+	switch {
+	case IsMissing(data.Precipitation):
+		return "missing"
+	case data.Precipitation <= 0:
+		return "none"
+	case data.Precipitation < 2.5:
+		return "light"
+	case data.Precipitation < 7.6:
+		return "moderate"
+	default:
+		return "heavy"
+	}
+}
+
+// PrecipitationType guesses the phase of the hour's precipitation: "none"
+// when dry or when precipitation or temperature is missing, otherwise
+// "snow", "mixed" or "rain". The WetBulb temperature is used when humidity
+// is available, since evaporative cooling lets snow survive above freezing:
+// snow at or below 0.5 °C, rain at or above 1.5 °C. Without humidity the
+// AirTemperature is used with thresholds of 0 °C and 3 °C.
+func (data HourlyWeatherData) PrecipitationType() string {
+	if IsMissing(data.Precipitation) || data.Precipitation <= 0 || IsMissing(data.AirTemperature) {
+		return "none"
+	}
+	temp, snowMax, rainMin := data.AirTemperature, float32(0), float32(3)
+	if !IsMissing(data.RelativeHumidity) {
+		temp, snowMax, rainMin = data.WetBulb(), 0.5, 1.5
+	}
+	switch {
+	case temp <= snowMax:
+		return "snow"
+	case temp >= rainMin:
+		return "rain"
+	default:
+		return "mixed"
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import "testing"
+
+func TestWaterYearPrecip(t *testing.T) {
+	sep30 := testRecord(t, 2019, 273, 12)
+	sep30.Precipitation = 1.5
+	oct1 := testRecord(t, 2019, 274, 12)
+	oct1.Precipitation = 2
+	jan1 := testRecord(t, 2020, 1, 12)
+	jan1.Precipitation = 3
+	gap := testRecord(t, 2020, 2, 12)
+	gap.Precipitation = MissingValue
+	// Water year 2021 has only a missing observation and must still appear.
+	empty := testRecord(t, 2020, 300, 12)
+	empty.Precipitation = MissingValue
+
+	data := []HourlyWeatherData{sep30, oct1, jan1, gap, empty}
+
+	totals := WaterYearPrecip(data)
+	if totals[2019] != 1.5 {
+		t.Errorf("water year 2019 = %v, want 1.5", totals[2019])
+	}
+	if totals[2020] != 5 {
+		t.Errorf("water year 2020 = %v, want 5", totals[2020])
+	}
+	if total, ok := totals[2021]; !ok || total != 0 {
+		t.Errorf("water year 2021 = %v, %v, want a zero total", total, ok)
+	}
+
+	coverage := WaterYearCoverage(data)
+	if want := 2.0 / (366 * 24); !approxEqual(coverage[2020], want, 1e-12) {
+		t.Errorf("water year 2020 coverage = %v, want %v", coverage[2020], want)
+	}
+	if want := 1.0 / (365 * 24); !approxEqual(coverage[2019], want, 1e-12) {
+		t.Errorf("water year 2019 coverage = %v, want %v", coverage[2019], want)
+	}
+	if cov, ok := coverage[2021]; !ok || cov != 0 {
+		t.Errorf("water year 2021 coverage = %v, %v, want zero", cov, ok)
+	}
+}
+
+func TestPrecipIntensityCategory(t *testing.T) {
+	tests := []struct {
+		rate float32
+		want string
+	}{
+		{0, "none"},
+		{0.25, "light"},
+		{2.49, "light"},
+		{2.5, "moderate"},
+		{7.59, "moderate"},
+		{7.6, "heavy"},
+		{30, "heavy"},
+		{MissingValue, "missing"},
+	}
+	for _, tt := range tests {
+		rec := HourlyWeatherData{Precipitation: tt.rate}
+		if got := rec.PrecipIntensityCategory(); got != tt.want {
+			t.Errorf("%v mm/h: PrecipIntensityCategory = %q, want %q", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestPrecipitationType(t *testing.T) {
+	tests := []struct {
+		name         string
+		temp, rh, mm float32
+		want         string
+	}{
+		{"cold", -2, 90, 1, "snow"},
+		{"warm", 10, 90, 1, "rain"},
+		{"borderline humid", 1.5, 90, 1, "mixed"},
+		{"above freezing but dry", 3, 40, 1, "snow"},
+		{"borderline without humidity", 1.5, MissingValue, 1, "mixed"},
+		{"warm without humidity", 3, MissingValue, 1, "rain"},
+		{"freezing without humidity", 0, MissingValue, 1, "snow"},
+		{"dry hour", -2, 90, 0, "none"},
+		{"missing temperature", MissingValue, 90, 1, "none"},
+	}
+	for _, tt := range tests {
+		rec := HourlyWeatherData{AirTemperature: tt.temp, RelativeHumidity: tt.rh, Precipitation: tt.mm}
+		if got := rec.PrecipitationType(); got != tt.want {
+			t.Errorf("%s: PrecipitationType = %q, want %q (wet bulb %v)", tt.name, got, tt.want, rec.WetBulb())
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// SpikeFlags returns the Time of every record whose field value lies more
+// than zThreshold standard deviations from the mean of the preceding window
+// valid values. Comparing against trailing values keeps a spike from
+// inflating the statistics it is judged by. Records are not flagged until a
+// full window is available or when the window has no variance.
+func SpikeFlags(data []HourlyWeatherData, field func(HourlyWeatherData) float32, zThreshold float64, window int) []time.Time {
+	flags := make([]time.Time, 0)
+	if window < 2 {
+		return flags
+	}
+	recent := make([]float64, 0, window)
+	for _, rec := range data {
+		val := field(rec)
+		if IsMissing(val) {
+			continue
+		}
+		x := float64(val)
+		if len(recent) == window {
+			mean, sd := meanStdDev(recent)
+			if sd > 0 && math.Abs(x-mean)/sd > zThreshold {
+				flags = append(flags, rec.Time)
+			}
+			recent = recent[1:]
+		}
+		recent = append(recent, x)
+	}
+	return flags
+}
+
+func meanStdDev(vals []float64) (float64, float64) {
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	mean := sum / float64(len(vals))
+	var sq float64
+	for _, v := range vals {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(vals)))
+}
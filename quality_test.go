@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestSpikeFlags(t *testing.T) {
+	var data []HourlyWeatherData
+	for hour := 1; hour <= 24; hour++ {
+		rec := testRecord(t, 2020, 100, hour)
+		// A gentle alternating signal around 20 °C.
+		rec.AirTemperature = 20 + float32(hour%2)
+		data = append(data, rec)
+	}
+	data[12].AirTemperature = 45 // injected spike
+	data[18].AirTemperature = MissingValue
+
+	temp := func(rec HourlyWeatherData) float32 { return rec.AirTemperature }
+	flags := SpikeFlags(data, temp, 4, 6)
+	if len(flags) != 1 || !flags[0].Equal(data[12].Time) {
+		t.Errorf("flags = %v, want only the spike at %v", flags, data[12].Time)
+	}
+
+	if flags := SpikeFlags(data[:6], temp, 4, 6); len(flags) != 0 {
+		t.Errorf("flagged %v before a full window was available", flags)
+	}
+	if flags := SpikeFlags(data, temp, 4, 1); len(flags) != 0 {
+		t.Errorf("window of 1 flagged %v", flags)
+	}
+}
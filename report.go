@@ -0,0 +1,126 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+type monthSummary struct {
+	Year               int
+	Month              time.Month
+	Days               int
+	MeanAirTemperature float32
+	MaxAirTemperature  float32
+	MinAirTemperature  float32
+	Precipitation      float32
+	Evapotranspiration float32
+}
+
+type reportExtreme struct {
+	Value float32
+	Time  time.Time
+	Found bool
+}
+
+type reportData struct {
+	Title       string
+	Records     int
+	Coverage    float64
+	Months      []monthSummary
+	Hottest     reportExtreme
+	Coldest     reportExtreme
+	HighestGust reportExtreme
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Records}} hourly records, {{printf "%.1f" .Coverage}}% of observations present.</p>
+<h2>Extremes</h2>
+<ul>
+{{with .Hottest}}{{if .Found}}<li>Highest air temperature: {{printf "%.1f" .Value}} °C at {{.Time.Format "2006-01-02 15:04"}}</li>{{end}}{{end}}
+{{with .Coldest}}{{if .Found}}<li>Lowest air temperature: {{printf "%.1f" .Value}} °C at {{.Time.Format "2006-01-02 15:04"}}</li>{{end}}{{end}}
+{{with .HighestGust}}{{if .Found}}<li>Highest wind speed: {{printf "%.1f" .Value}} m/s at {{.Time.Format "2006-01-02 15:04"}}</li>{{end}}{{end}}
+</ul>
+<h2>Monthly summary</h2>
+<table>
+<tr><th>Month</th><th>Days</th><th>Mean °C</th><th>Max °C</th><th>Min °C</th><th>Precipitation mm</th><th>ET mm</th></tr>
+{{range .Months}}<tr><td>{{.Month}} {{.Year}}</td><td>{{.Days}}</td><td>{{printf "%.1f" .MeanAirTemperature}}</td><td>{{printf "%.1f" .MaxAirTemperature}}</td><td>{{printf "%.1f" .MinAirTemperature}}</td><td>{{printf "%.1f" .Precipitation}}</td><td>{{printf "%.1f" .Evapotranspiration}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTMLReport renders a self-contained HTML page summarizing data: its
+// coverage, temperature and wind extremes, and a month by month table.
+func WriteHTMLReport(w io.Writer, title string, data []HourlyWeatherData) error {
+	report := reportData{
+		Title:    title,
+		Records:  len(data),
+		Coverage: fieldCoverage(data) * 100,
+		Months:   summarizeMonths(AggregateDaily(data)),
+	}
+
+	for _, rec := range data {
+		if !IsMissing(rec.AirTemperature) {
+			if !report.Hottest.Found || rec.AirTemperature > report.Hottest.Value {
+				report.Hottest = reportExtreme{rec.AirTemperature, rec.Time, true}
+			}
+			if !report.Coldest.Found || rec.AirTemperature < report.Coldest.Value {
+				report.Coldest = reportExtreme{rec.AirTemperature, rec.Time, true}
+			}
+		}
+		if !IsMissing(rec.WindSpeedMax) {
+			if !report.HighestGust.Found || rec.WindSpeedMax > report.HighestGust.Value {
+				report.HighestGust = reportExtreme{rec.WindSpeedMax, rec.Time, true}
+			}
+		}
+	}
+
+	return reportTemplate.Execute(w, report)
+}
+
+func summarizeMonths(daily []DailyAggregate) []monthSummary {
+	months := make([]monthSummary, 0)
+	var sums []float32
+	for _, day := range daily {
+		year, month := day.Date.Year(), day.Date.Month()
+		last := len(months) - 1
+		if last < 0 || months[last].Year != year || months[last].Month != month {
+			months = append(months, monthSummary{Year: year, Month: month})
+			sums = append(sums, 0)
+			last++
+		}
+		m := &months[last]
+		m.Precipitation += day.Precipitation
+		m.Evapotranspiration += day.Evapotranspiration
+		if IsMissing(day.MeanAirTemperature) {
+			continue
+		}
+		if m.Days == 0 || day.MaxAirTemperature > m.MaxAirTemperature {
+			m.MaxAirTemperature = day.MaxAirTemperature
+		}
+		if m.Days == 0 || day.MinAirTemperature < m.MinAirTemperature {
+			m.MinAirTemperature = day.MinAirTemperature
+		}
+		sums[last] += day.MeanAirTemperature
+		m.Days++
+	}
+	for i := range months {
+		if months[i].Days > 0 {
+			months[i].MeanAirTemperature = sums[i] / float32(months[i].Days)
+		}
+	}
+	return months
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	var data []HourlyWeatherData
+	for _, day := range []int{31, 32} {
+		for hour := 1; hour <= 24; hour++ {
+			rec := testRecord(t, 2020, day, hour)
+			rec.AirTemperature = 10
+			rec.WindSpeedMax = 3
+			data = append(data, rec)
+		}
+	}
+	data[15].AirTemperature = 27.5 // January 31, 16:00
+	data[30].AirTemperature = -2.25
+	data[30].WindSpeedMax = 14.2 // February 1, 07:00
+	data[40].Precipitation = 6.4
+	data[47].SolarRadiation = MissingValue
+
+	var buf bytes.Buffer
+	if err := WriteHTMLReport(&buf, "Tucson <2020>", data); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		"<title>Tucson &lt;2020&gt;</title>",
+		"48 hourly records",
+		"Highest air temperature: 27.5 °C at 2020-01-31 16:00",
+		"Lowest air temperature: -2.2 °C at 2020-02-01 07:00",
+		"Highest wind speed: 14.2 m/s at 2020-02-01 07:00",
+		"<td>January 2020</td><td>1</td>",
+		"<td>February 2020</td><td>1</td>",
+		"<td>6.4</td>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// ToTimeMap indexes records by Time. Keys are normalized to UTC so that equal
+// instants match regardless of Location; look records up with t.UTC(). When
+// several records share a Time the last one in data wins.
+func ToTimeMap(data []HourlyWeatherData) map[time.Time]HourlyWeatherData {
+	m := make(map[time.Time]HourlyWeatherData, len(data))
+	for _, rec := range data {
+		m[rec.Time.UTC()] = rec
+	}
+	return m
+}
+
+// LongestContinuousRun finds the longest stretch of records, in the order
+// given, that are spaced exactly one hour apart and whose field value is not
+// missing. The earliest run wins a tie; length is zero when no record has a
+// valid value.
+func LongestContinuousRun(data []HourlyWeatherData, field func(HourlyWeatherData) float32) (start, end time.Time, length int) {
+	runStart, runLength := 0, 0
+	for i, rec := range data {
+		if IsMissing(field(rec)) {
+			runLength = 0
+			continue
+		}
+		if runLength > 0 && rec.Time.Sub(data[i-1].Time) == time.Hour {
+			runLength++
+		} else {
+			runStart, runLength = i, 1
+		}
+		if runLength > length {
+			start, end, length = data[runStart].Time, rec.Time, runLength
+		}
+	}
+	return start, end, length
+}
+
+// RunningExtremes returns, for each record, the highest and lowest field value
+// seen since the most recent reset. reset is consulted at every record's Time
+// and, when it returns true, the running values restart from that record.
+// Missing values are skipped; positions before any valid value since the last
+// reset hold MissingValue.
+func RunningExtremes(data []HourlyWeatherData, field func(HourlyWeatherData) float32, reset func(time.Time) bool) (max, min []float32) {
+	max = make([]float32, len(data))
+	min = make([]float32, len(data))
+	hi, lo := MissingValue, MissingValue
+	found := false
+	for i, rec := range data {
+		if i > 0 && reset(rec.Time) {
+			hi, lo, found = MissingValue, MissingValue, false
+		}
+		if val := field(rec); !IsMissing(val) {
+			if !found || val > hi {
+				hi = val
+			}
+			if !found || val < lo {
+				lo = val
+			}
+			found = true
+		}
+		max[i], min[i] = hi, lo
+	}
+	return max, min
+}
+
+// MergeHourlyData combines previously stored records with a fresh download,
+// keeping one record per Time and preferring the fresh one. The result is
+// sorted by Time.
+func MergeHourlyData(cached, fresh []HourlyWeatherData) []HourlyWeatherData {
+	byTime := make(map[time.Time]HourlyWeatherData, len(cached)+len(fresh))
+	for _, rec := range cached {
+		byTime[rec.Time.UTC()] = rec
+	}
+	for _, rec := range fresh {
+		byTime[rec.Time.UTC()] = rec
+	}
+
+	merged := make([]HourlyWeatherData, 0, len(byTime))
+	for _, rec := range byTime {
+		merged = append(merged, rec)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Time.Before(merged[j].Time)
+	})
+	return merged
+}
+
+// PersistenceForecast builds the persistence baseline for a horizon in hours:
+// each record's observations are carried forward as the forecast for the
+// record horizon hours later. Every returned record keeps the Year, Day,
+// Hour and Time of the verifying observation, so it lines up with the
+// actual series for skill scoring. Records whose target time is absent from
+// data produce no forecast.
+func PersistenceForecast(data []HourlyWeatherData, horizon int) []HourlyWeatherData {
+	byTime := ToTimeMap(data)
+	lead := time.Duration(horizon) * time.Hour
+
+	forecast := make([]HourlyWeatherData, 0, len(data))
+	for _, rec := range data {
+		target, ok := byTime[rec.Time.Add(lead).UTC()]
+		if !ok {
+			continue
+		}
+		predicted := rec
+		predicted.Year, predicted.Day, predicted.Hour, predicted.Time = target.Year, target.Day, target.Hour, target.Time
+		forecast = append(forecast, predicted)
+	}
+	return forecast
+}
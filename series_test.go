@@ -0,0 +1,138 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToTimeMap(t *testing.T) {
+	first := testRecord(t, 2020, 10, 5)
+	first.AirTemperature = 10
+	second := testRecord(t, 2020, 10, 6)
+	second.AirTemperature = 11
+	duplicate := first
+	duplicate.AirTemperature = 12
+
+	m := ToTimeMap([]HourlyWeatherData{first, second, duplicate})
+	if len(m) != 2 {
+		t.Fatalf("got %d entries, want 2", len(m))
+	}
+
+	// Look up with a Time in another Location to show keys are normalized.
+	mst := time.FixedZone("MST", -7*60*60)
+	if rec, ok := m[second.Time.In(mst).UTC()]; !ok || rec.AirTemperature != 11 {
+		t.Errorf("lookup of second record = %v, %v", rec, ok)
+	}
+	if rec := m[first.Time.UTC()]; rec.AirTemperature != 12 {
+		t.Errorf("duplicate Time kept AirTemperature %v, want the last record's 12", rec.AirTemperature)
+	}
+}
+
+func TestLongestContinuousRun(t *testing.T) {
+	// Hours 1-3 valid, 4 missing, 5-9 valid, hour 10 absent, 11-15 valid.
+	var data []HourlyWeatherData
+	for hour := 1; hour <= 15; hour++ {
+		if hour == 10 {
+			continue
+		}
+		rec := testRecord(t, 2020, 50, hour)
+		rec.AirTemperature = 15
+		if hour == 4 {
+			rec.AirTemperature = MissingValue
+		}
+		data = append(data, rec)
+	}
+	temp := func(rec HourlyWeatherData) float32 { return rec.AirTemperature }
+
+	start, end, length := LongestContinuousRun(data, temp)
+	if length != 5 {
+		t.Fatalf("length = %d, want 5", length)
+	}
+	// Two runs of five tie; the earlier one wins.
+	if !start.Equal(data[4].Time) || !end.Equal(data[8].Time) {
+		t.Errorf("run = %v to %v, want %v to %v", start, end, data[4].Time, data[8].Time)
+	}
+
+	missing := func(HourlyWeatherData) float32 { return MissingValue }
+	if _, _, length := LongestContinuousRun(data, missing); length != 0 {
+		t.Errorf("all-missing length = %d, want 0", length)
+	}
+}
+
+func TestRunningExtremesMonthlyReset(t *testing.T) {
+	// Days 30-33 of 2020 straddle the end of January.
+	temps := []float32{10, 15, MissingValue, 8, 12}
+	days := []int{30, 31, 32, 32, 33}
+	hours := []int{12, 12, 12, 18, 12}
+	var data []HourlyWeatherData
+	for i, temp := range temps {
+		rec := testRecord(t, 2020, days[i], hours[i])
+		rec.AirTemperature = temp
+		data = append(data, rec)
+	}
+	newMonth := func(ts time.Time) bool { return ts.Day() == 1 && ts.Hour() == 12 }
+
+	max, min := RunningExtremes(data, func(rec HourlyWeatherData) float32 { return rec.AirTemperature }, newMonth)
+	wantMax := []float32{10, 15, MissingValue, 8, 12}
+	wantMin := []float32{10, 10, MissingValue, 8, 8}
+	for i := range data {
+		if max[i] != wantMax[i] || min[i] != wantMin[i] {
+			t.Errorf("record %d: max %v min %v, want %v and %v", i, max[i], min[i], wantMax[i], wantMin[i])
+		}
+	}
+}
+
+func TestMergeHourlyData(t *testing.T) {
+	var cached, fresh []HourlyWeatherData
+	for hour := 1; hour <= 6; hour++ {
+		rec := testRecord(t, 2021, 5, hour)
+		rec.AirTemperature = 10
+		cached = append(cached, rec)
+	}
+	for hour := 8; hour >= 4; hour-- { // overlaps hours 4-6, out of order
+		rec := testRecord(t, 2021, 5, hour)
+		rec.AirTemperature = 20
+		fresh = append(fresh, rec)
+	}
+
+	merged := MergeHourlyData(cached, fresh)
+	if len(merged) != 8 {
+		t.Fatalf("got %d records, want 8", len(merged))
+	}
+	for i, rec := range merged {
+		want := float32(10)
+		if rec.Hour >= 4 {
+			want = 20
+		}
+		if rec.Hour != i+1 || rec.AirTemperature != want {
+			t.Errorf("record %d = hour %d at %v, want hour %d at %v", i, rec.Hour, rec.AirTemperature, i+1, want)
+		}
+	}
+}
+
+func TestPersistenceForecast(t *testing.T) {
+	// Hours 22-24 of one day and 1-2 of the next, with hour 1 absent.
+	var data []HourlyWeatherData
+	for _, dh := range [][2]int{{80, 22}, {80, 23}, {80, 24}, {81, 2}} {
+		rec := testRecord(t, 2020, dh[0], dh[1])
+		rec.AirTemperature = float32(dh[1])
+		data = append(data, rec)
+	}
+
+	forecast := PersistenceForecast(data, 2)
+	// 22 verifies at 24; 23 would verify at day 81 hour 1, which is
+	// missing; 24 verifies at day 81 hour 2.
+	if len(forecast) != 2 {
+		t.Fatalf("got %d forecasts, want 2", len(forecast))
+	}
+	if f := forecast[0]; f.Day != 80 || f.Hour != 24 || f.AirTemperature != 22 || !f.Time.Equal(data[2].Time) {
+		t.Errorf("first forecast = day %d hour %d at %v", f.Day, f.Hour, f.AirTemperature)
+	}
+	if f := forecast[1]; f.Day != 81 || f.Hour != 2 || f.AirTemperature != 24 || !f.Time.Equal(data[3].Time) {
+		t.Errorf("second forecast = day %d hour %d at %v", f.Day, f.Hour, f.AirTemperature)
+	}
+
+	if got := PersistenceForecast(data, 0); len(got) != len(data) || got[1] != data[1] {
+		t.Errorf("zero horizon should reproduce the series")
+	}
+}
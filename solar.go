@@ -0,0 +1,163 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+type DailySolarPeak struct {
+	Year           int
+	Day            int
+	Hour           int
+	SolarRadiation float32
+}
+
+// SolarPeakHour reports the hour of maximum solar radiation for each day,
+// ignoring missing values. Days without any valid reading are omitted. Pass a
+// peak to SolarNoonOffset to compare it with solar noon.
+func SolarPeakHour(hourly []HourlyWeatherData) []DailySolarPeak {
+	peaks := make([]DailySolarPeak, 0)
+	for _, day := range groupByDay(hourly) {
+		found := false
+		var peak DailySolarPeak
+		for _, rec := range day {
+			if IsMissing(rec.SolarRadiation) {
+				continue
+			}
+			if !found || rec.SolarRadiation > peak.SolarRadiation {
+				peak = DailySolarPeak{rec.Year, rec.Day, rec.Hour, rec.SolarRadiation}
+				found = true
+			}
+		}
+		if found {
+			peaks = append(peaks, peak)
+		}
+	}
+	return peaks
+}
+
+// solarConstant is in MJ/(m²·min).
+const solarConstant = 0.0820
+
+func solarDeclination(day int) float64 {
+	return 0.409 * math.Sin(2*math.Pi/365*float64(day)-1.39)
+}
+
+func inverseRelativeDistance(day int) float64 {
+	return 1 + 0.033*math.Cos(2*math.Pi/365*float64(day))
+}
+
+// ExtraterrestrialRadiation returns the daily extraterrestrial radiation in
+// MJ/m² for a latitude in decimal degrees and a day of year (FAO-56 eq. 21).
+func ExtraterrestrialRadiation(latitude float64, day int) float64 {
+	phi := latitude * math.Pi / 180
+	delta := solarDeclination(day)
+	ws := math.Acos(math.Max(-1, math.Min(1, -math.Tan(phi)*math.Tan(delta))))
+	return 24 * 60 / math.Pi * solarConstant * inverseRelativeDistance(day) *
+		(ws*math.Sin(phi)*math.Sin(delta) + math.Cos(phi)*math.Cos(delta)*math.Sin(ws))
+}
+
+// arizonaStandardMeridian is the central meridian of Mountain Standard Time,
+// which Arizona observes year round, in degrees west.
+const arizonaStandardMeridian = 105.0
+
+// seasonalCorrection is the equation of time in hours (FAO-56 eq. 32).
+func seasonalCorrection(day int) float64 {
+	b := 2 * math.Pi * float64(day-81) / 364
+	return 0.1645*math.Sin(2*b) - 0.1255*math.Cos(b) - 0.025*math.Sin(b)
+}
+
+// solarHourAngle returns the solar hour angle in radians at clockHour, in
+// decimal hours of Mountain Standard Time, for a longitude in decimal degrees
+// east (FAO-56 eq. 31).
+func solarHourAngle(day int, clockHour, longitude float64) float64 {
+	return math.Pi / 12 * (clockHour + 0.06667*(arizonaStandardMeridian+longitude) + seasonalCorrection(day) - 12)
+}
+
+// SolarNoon returns the clock time of solar noon, in decimal hours of Mountain
+// Standard Time, on day for a longitude in decimal degrees east.
+func SolarNoon(day int, longitude float64) float64 {
+	return 12 - 0.06667*(arizonaStandardMeridian+longitude) - seasonalCorrection(day)
+}
+
+// SolarNoonOffset returns how many hours the middle of peak's hour falls after
+// solar noon at station. An offset that stays well away from zero on clear
+// days points to a tilted sensor or to shading: positive when mornings are
+// shaded or the sensor leans west, negative for the reverse. ok is false for a
+// station not in the station table.
+func SolarNoonOffset(peak DailySolarPeak, station WeatherStation) (offset float64, ok bool) {
+	info, ok := LookupStation(station)
+	if !ok {
+		return 0, false
+	}
+	return float64(peak.Hour) - 0.5 - SolarNoon(peak.Day, info.Longitude), true
+}
+
+// hourlyExtraterrestrialRadiation returns the extraterrestrial radiation in
+// MJ/m² over the hour ending at hour (FAO-56 eq. 28), or zero when the sun is
+// below the horizon for the whole hour.
+func hourlyExtraterrestrialRadiation(latitude, longitude float64, day, hour int) float64 {
+	phi := latitude * math.Pi / 180
+	delta := solarDeclination(day)
+	ws := math.Acos(math.Max(-1, math.Min(1, -math.Tan(phi)*math.Tan(delta))))
+	omega := solarHourAngle(day, float64(hour)-0.5, longitude)
+	w1 := math.Max(omega-math.Pi/24, -ws)
+	w2 := math.Min(omega+math.Pi/24, ws)
+	if w1 >= w2 {
+		return 0
+	}
+	return 12 * 60 / math.Pi * solarConstant * inverseRelativeDistance(day) *
+		((w2-w1)*math.Sin(phi)*math.Sin(delta) + math.Cos(phi)*math.Cos(delta)*(math.Sin(w2)-math.Sin(w1)))
+}
+
+// ClearnessIndex returns the hourly clearness index Kt, the ratio of measured
+// SolarRadiation to extraterrestrial radiation at station. Night hours, hours
+// with missing radiation and every hour of a station not in the station table
+// are NaN.
+func ClearnessIndex(data []HourlyWeatherData, station WeatherStation) []float64 {
+	kt := make([]float64, len(data))
+	info, known := LookupStation(station)
+	for i, rec := range data {
+		if !known {
+			kt[i] = math.NaN()
+			continue
+		}
+		ra := hourlyExtraterrestrialRadiation(info.Latitude, info.Longitude, rec.Day, rec.Hour)
+		if ra <= 0 || IsMissing(rec.SolarRadiation) {
+			kt[i] = math.NaN()
+			continue
+		}
+		kt[i] = float64(rec.SolarRadiation) / ra
+	}
+	return kt
+}
+
+var mountainStandardTime = time.FixedZone("MST", -7*60*60)
+
+// IsDaytime reports whether the sun's center is above the horizon at t for a
+// site at latitude and longitude in decimal degrees (east positive).
+func IsDaytime(t time.Time, latitude, longitude float64) bool {
+	mst := t.In(mountainStandardTime)
+	day := mst.YearDay()
+	clock := float64(mst.Hour()) + float64(mst.Minute())/60 + float64(mst.Second())/3600
+	phi := latitude * math.Pi / 180
+	delta := solarDeclination(day)
+	omega := solarHourAngle(day, clock, longitude)
+	return math.Sin(phi)*math.Sin(delta)+math.Cos(phi)*math.Cos(delta)*math.Cos(omega) > 0
+}
+
+// DaylightOnly keeps the records whose Time falls between sunrise and sunset
+// at station. It returns no records for a station not in the station table.
+func DaylightOnly(data []HourlyWeatherData, station WeatherStation) []HourlyWeatherData {
+	day := make([]HourlyWeatherData, 0, len(data)/2)
+	info, ok := LookupStation(station)
+	if !ok {
+		return day
+	}
+	for _, rec := range data {
+		if IsDaytime(rec.Time, info.Latitude, info.Longitude) {
+			day = append(day, rec)
+		}
+	}
+	return day
+}
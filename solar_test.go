@@ -0,0 +1,144 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolarPeakHour(t *testing.T) {
+	day := make([]HourlyWeatherData, 0, 24)
+	for hour := 1; hour <= 24; hour++ {
+		rec := testRecord(t, 2020, 172, hour)
+		if hour >= 7 && hour <= 19 {
+			rec.SolarRadiation = float32(7 - abs(hour-13))
+		}
+		day = append(day, rec)
+	}
+	day[12].SolarRadiation = MissingValue // would otherwise win as 999
+
+	peaks := SolarPeakHour(day)
+	if len(peaks) != 1 {
+		t.Fatalf("SolarPeakHour returned %d days, want 1", len(peaks))
+	}
+	if peaks[0].Hour != 12 && peaks[0].Hour != 14 {
+		t.Errorf("peak hour = %d, want the hour beside the missing noon reading", peaks[0].Hour)
+	}
+	if peaks[0].SolarRadiation != 6 {
+		t.Errorf("peak radiation = %v, want 6", peaks[0].SolarRadiation)
+	}
+
+	day[12].SolarRadiation = 7
+	if peaks := SolarPeakHour(day); peaks[0].Hour != 13 {
+		t.Errorf("peak hour = %d, want 13", peaks[0].Hour)
+	}
+}
+
+func TestSolarNoonOffset(t *testing.T) {
+	// Solar noon at Tucson on the solstice is near 12:24 MST.
+	if noon := SolarNoon(172, -110.9454); !approxEqual(noon, 12.42, 0.01) {
+		t.Errorf("SolarNoon = %v, want 12.42", noon)
+	}
+
+	centered := DailySolarPeak{Year: 2020, Day: 172, Hour: 13}
+	if offset, ok := SolarNoonOffset(centered, Tucson); !ok || !approxEqual(offset, 0.08, 0.01) {
+		t.Errorf("centered peak offset = %v, %v, want 0.08", offset, ok)
+	}
+
+	// Morning shading pushes the peak into the hour ending at 16.
+	day := make([]HourlyWeatherData, 0, 24)
+	for hour := 1; hour <= 24; hour++ {
+		rec := testRecord(t, 2020, 172, hour)
+		if hour >= 7 && hour <= 19 {
+			rec.SolarRadiation = float32(10 - abs(hour-16))
+		}
+		if hour < 12 {
+			rec.SolarRadiation /= 2
+		}
+		day = append(day, rec)
+	}
+	peaks := SolarPeakHour(day)
+	if len(peaks) != 1 || peaks[0].Hour != 16 {
+		t.Fatalf("SolarPeakHour = %+v, want a peak at hour 16", peaks)
+	}
+	if offset, ok := SolarNoonOffset(peaks[0], Tucson); !ok || !approxEqual(offset, 3.08, 0.01) {
+		t.Errorf("shifted peak offset = %v, %v, want 3.08", offset, ok)
+	}
+
+	if _, ok := SolarNoonOffset(centered, WeatherStation(99)); ok {
+		t.Error("unknown station: ok = true, want false")
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestClearnessIndex(t *testing.T) {
+	var day []HourlyWeatherData
+	for hour := 1; hour <= 24; hour++ {
+		rec := testRecord(t, 2021, 172, hour)
+		if hour == 13 {
+			// A clear solstice noon at Tucson, about 980 W/m².
+			rec.SolarRadiation = 3.52
+		}
+		day = append(day, rec)
+	}
+	day[13].SolarRadiation = MissingValue
+
+	// Solar noon at Tucson falls near 12:24 MST, so extraterrestrial
+	// radiation peaks in the hour ending at 13, at 4.69 MJ/m².
+	if ra := hourlyExtraterrestrialRadiation(32.2804, -110.9454, 172, 13); !approxEqual(ra, 4.692, 0.005) {
+		t.Errorf("noon extraterrestrial radiation = %v, want 4.692", ra)
+	}
+
+	kt := ClearnessIndex(day, Tucson)
+	if !approxEqual(kt[12], 0.75, 0.005) {
+		t.Errorf("noon Kt = %v, want 0.75", kt[12])
+	}
+	if !math.IsNaN(kt[0]) || !math.IsNaN(kt[13]) {
+		t.Errorf("night and missing hours: Kt = %v and %v, want NaN", kt[0], kt[13])
+	}
+	if kt[10] != 0 {
+		t.Errorf("dark daytime hour Kt = %v, want 0", kt[10])
+	}
+	if kt := ClearnessIndex(day, WeatherStation(99)); !math.IsNaN(kt[12]) {
+		t.Errorf("unknown station Kt = %v, want NaN", kt[12])
+	}
+}
+
+func TestDaylightOnly(t *testing.T) {
+	var summer, winter []HourlyWeatherData
+	for hour := 1; hour <= 24; hour++ {
+		summer = append(summer, testRecord(t, 2021, 172, hour))
+		winter = append(winter, testRecord(t, 2021, 355, hour))
+	}
+
+	// At Tucson the summer sun is up from about 05:20 to 19:30 MST and the
+	// winter sun from about 07:20 to 17:25, so on-the-hour records from 06:00
+	// to 19:00 and from 08:00 to 17:00 are kept.
+	tests := []struct {
+		name        string
+		data        []HourlyWeatherData
+		first, last int
+	}{
+		{"summer solstice", summer, 6, 19},
+		{"winter solstice", winter, 8, 17},
+	}
+	for _, tt := range tests {
+		day := DaylightOnly(tt.data, Tucson)
+		if len(day) != tt.last-tt.first+1 {
+			t.Errorf("%s: kept %d hours, want %d", tt.name, len(day), tt.last-tt.first+1)
+			continue
+		}
+		if day[0].Hour != tt.first || day[len(day)-1].Hour != tt.last {
+			t.Errorf("%s: kept hours %d to %d, want %d to %d", tt.name, day[0].Hour, day[len(day)-1].Hour, tt.first, tt.last)
+		}
+	}
+
+	if day := DaylightOnly(summer, WeatherStation(99)); len(day) != 0 {
+		t.Errorf("unknown station kept %d hours", len(day))
+	}
+}
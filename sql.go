@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// sqlTable is the table WriteSQL creates and populates.
+const sqlTable = "hourly_weather"
+
+// WriteSQL writes a CREATE TABLE statement followed by one INSERT per record
+// for station, in portable SQL that SQLite accepts. Columns are the station
+// ID, the record's Time in RFC 3339, and every HourlyWeatherData field in
+// snake case; missing observations are written as NULL.
+func WriteSQL(w io.Writer, station WeatherStation, data []HourlyWeatherData) error {
+	t := reflect.TypeOf(HourlyWeatherData{})
+	columns := []string{"station INTEGER NOT NULL", "time TEXT NOT NULL"}
+	names := []string{"station", "time"}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		var kind string
+		switch field.Type.Kind() {
+		case reflect.Int:
+			kind = "INTEGER"
+		case reflect.Float32:
+			kind = "REAL"
+		default:
+			continue
+		}
+		name := snakeCase(field.Name)
+		columns = append(columns, name+" "+kind)
+		names = append(names, name)
+	}
+
+	if _, err := fmt.Fprintf(w, "CREATE TABLE IF NOT EXISTS %s (\n\t%s,\n\tPRIMARY KEY (station, time)\n);\n",
+		sqlTable, strings.Join(columns, ",\n\t")); err != nil {
+		return err
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (", sqlTable, strings.Join(names, ", "))
+	for _, rec := range data {
+		s := reflect.ValueOf(rec)
+		values := []string{strconv.Itoa(int(station)), "'" + rec.Time.Format(time.RFC3339) + "'"}
+		for i := 0; i < s.NumField(); i++ {
+			field := s.Field(i)
+			switch field.Kind() {
+			case reflect.Int:
+				values = append(values, strconv.Itoa(int(field.Int())))
+			case reflect.Float32:
+				val := float32(field.Float())
+				if IsMissing(val) {
+					values = append(values, "NULL")
+				} else {
+					values = append(values, strconv.FormatFloat(float64(val), 'f', -1, 32))
+				}
+			}
+		}
+		if _, err := io.WriteString(w, insert+strings.Join(values, ", ")+");\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snakeCase converts a Go field name such as SoilTempFourInches to
+// soil_temp_four_inches.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
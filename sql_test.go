@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Year":                 "year",
+		"AirTemperature":       "air_temperature",
+		"SoilTempFourInches":   "soil_temp_four_inches",
+		"VaporPressureDeficit": "vapor_pressure_deficit",
+	}
+	for name, want := range tests {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// parseSQLInsert reads one INSERT statement written by WriteSQL back into its
+// station and record, mapping each column onto the field of the same snake
+// case name and NULL onto MissingValue.
+func parseSQLInsert(t *testing.T, stmt string) (int, HourlyWeatherData) {
+	t.Helper()
+	prefix := "INSERT INTO " + sqlTable + " ("
+	if !strings.HasPrefix(stmt, prefix) || !strings.HasSuffix(stmt, ");") {
+		t.Fatalf("malformed INSERT: %s", stmt)
+	}
+	cols, vals, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(stmt, prefix), ");"), ") VALUES (")
+	if !ok {
+		t.Fatalf("INSERT without VALUES: %s", stmt)
+	}
+	names, values := strings.Split(cols, ", "), strings.Split(vals, ", ")
+	if len(names) != len(values) {
+		t.Fatalf("%d columns but %d values: %s", len(names), len(values), stmt)
+	}
+
+	fields := make(map[string]int)
+	rt := reflect.TypeOf(HourlyWeatherData{})
+	for i := 0; i < rt.NumField(); i++ {
+		fields[snakeCase(rt.Field(i).Name)] = i
+	}
+
+	var station int
+	var rec HourlyWeatherData
+	s := reflect.ValueOf(&rec).Elem()
+	for i, name := range names {
+		val := values[i]
+		switch name {
+		case "station":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				t.Fatalf("station %q: %v", val, err)
+			}
+			station = n
+			continue
+		case "time":
+			if !strings.HasPrefix(val, "'") || !strings.HasSuffix(val, "'") {
+				t.Fatalf("time %s is not a quoted string", val)
+			}
+			ts, err := time.Parse(time.RFC3339, strings.Trim(val, "'"))
+			if err != nil {
+				t.Fatalf("time %s: %v", val, err)
+			}
+			rec.Time = ts
+			continue
+		}
+		f, ok := fields[name]
+		if !ok {
+			t.Fatalf("column %q has no matching field", name)
+		}
+		field := s.Field(f)
+		switch field.Kind() {
+		case reflect.Int:
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				t.Fatalf("%s %q: %v", name, val, err)
+			}
+			field.SetInt(int64(n))
+		case reflect.Float32:
+			if val == "NULL" {
+				field.SetFloat(float64(MissingValue))
+				continue
+			}
+			x, err := strconv.ParseFloat(val, 32)
+			if err != nil {
+				t.Fatalf("%s %q: %v", name, val, err)
+			}
+			field.SetFloat(x)
+		}
+	}
+	return station, rec
+}
+
+func TestWriteSQL(t *testing.T) {
+	data := sampleRecords(t)
+	data[0].SolarRadiation = MissingValue
+	data[1].AirTemperature, data[1].DewpointHourAverage = MissingValue, MissingValue
+	data[1].Precipitation = 0.254
+
+	var buf bytes.Buffer
+	if err := WriteSQL(&buf, Tucson, data); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	create, inserts, ok := strings.Cut(out, ");\n")
+	if !ok || !strings.HasPrefix(create, "CREATE TABLE IF NOT EXISTS "+sqlTable+" (") {
+		t.Fatalf("output does not start with a CREATE TABLE: %.200s", out)
+	}
+	for _, col := range []string{
+		"station INTEGER NOT NULL",
+		"time TEXT NOT NULL",
+		"year INTEGER",
+		"air_temperature REAL",
+		"soil_temp_four_inches REAL",
+		"dewpoint_hour_average REAL",
+		"PRIMARY KEY (station, time)",
+	} {
+		if !strings.Contains(create, "\t"+col+",\n") && !strings.Contains(create, "\t"+col+"\n") {
+			t.Errorf("CREATE TABLE is missing %q:\n%s", col, create)
+		}
+	}
+	// The station and time columns, every field but Time, and the key.
+	numeric := reflect.TypeOf(HourlyWeatherData{}).NumField() - 1
+	if got, want := strings.Count(create, "\n\t"), 2+numeric+1; got != want {
+		t.Errorf("CREATE TABLE has %d definitions, want %d", got, want)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(inserts, "\n"), "\n")
+	if len(lines) != len(data) {
+		t.Fatalf("got %d INSERT statements, want %d", len(lines), len(data))
+	}
+	if n := strings.Count(lines[0], "NULL"); n != 1 {
+		t.Errorf("first record has %d NULLs, want 1: %s", n, lines[0])
+	}
+	if n := strings.Count(lines[1], "NULL"); n != 2 {
+		t.Errorf("second record has %d NULLs, want 2: %s", n, lines[1])
+	}
+	if strings.Contains(out, "999") {
+		t.Errorf("missing sentinel written as a value:\n%s", out)
+	}
+
+	keys := make(map[string]bool)
+	for i, line := range lines {
+		station, rec := parseSQLInsert(t, line)
+		if station != int(Tucson) {
+			t.Errorf("record %d: station = %d, want %d", i, station, Tucson)
+		}
+		if !sameRecord(rec, data[i]) {
+			t.Errorf("record %d did not round-trip:\n got %+v\nwant %+v", i, rec, data[i])
+		}
+		key := strconv.Itoa(station) + " " + rec.Time.Format(time.RFC3339)
+		if keys[key] {
+			t.Errorf("record %d repeats primary key %s", i, key)
+		}
+		keys[key] = true
+	}
+	if !strings.Contains(lines[0], "'2020-01-01T01:00:00-07:00'") {
+		t.Errorf("time not written in RFC 3339 Arizona time: %s", lines[0])
+	}
+}
+
+func TestWriteSQLEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSQL(&buf, Tucson, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "INSERT") || !strings.HasPrefix(buf.String(), "CREATE TABLE") {
+		t.Errorf("empty export = %q, want only the CREATE TABLE", buf.String())
+	}
+}
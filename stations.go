@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type StationInfo struct {
+	Station WeatherStation `json:"id"`
+	Name    string         `json:"name"`
+	// Latitude and Longitude are in decimal degrees, west longitudes negative.
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	// Elevation is the station's height above sea level in meters.
+	Elevation float64 `json:"elevation"`
+}
+
+var stations = []StationInfo{
+	{Aguila, "Aguila", 33.9416, -113.1895, 657},
+	{Bonita, "Bonita", 32.4605, -109.9303, 1345},
+	{Bowie, "Bowie", 32.3261, -109.4896, 1147},
+	{Buckeye, "Buckeye", 33.4120, -112.6852, 304},
+	{Coolidge, "Coolidge", 32.9812, -111.6064, 421},
+	{DesertRidge, "Desert Ridge", 33.6889, -111.9625, 502},
+	{Harquahala, "Harquahala", 33.4834, -113.1177, 352},
+	{Maricopa, "Maricopa", 33.0687, -111.9718, 361},
+	{Mohave, "Mohave", 34.9708, -114.6031, 155},
+	{Mohave2, "Mohave 2", 35.0306, -114.5968, 151},
+	{FtMohave, "Ft Mohave", 35.0161, -114.5761, 152},
+	{Paloma, "Paloma", 32.9259, -112.8965, 220},
+	{Parker, "Parker", 34.0272, -114.3119, 106},
+	{Parker2, "Parker 2", 33.9866, -114.4378, 104},
+	{Payson, "Payson", 34.2299, -111.3431, 1495},
+	{PhoenixGreenway, "Phoenix Greenway", 33.6213, -112.1080, 401},
+	{PhoenixEncanto, "Phoenix Encanto", 33.4792, -112.0963, 338},
+	{QueenCreek, "Queen Creek", 33.1925, -111.5281, 445},
+	{Roll, "Roll", 32.8107, -113.8018, 118},
+	{Safford, "Safford", 32.8124, -109.6811, 903},
+	{Sahuarita, "Sahuarita", 31.9176, -110.9699, 820},
+	{Salome, "Salome", 33.7768, -113.6386, 560},
+	{SanSimon, "San Simon", 32.2704, -109.2363, 1107},
+	{Tucson, "Tucson", 32.2804, -110.9454, 713},
+	{Willcox, "Willcox", 32.0178, -109.8877, 1274},
+	{YumaNorth, "Yuma North", 32.7340, -114.5272, 43},
+	{YumaSouth, "Yuma South", 32.6156, -114.6328, 60},
+	{YumaValley, "Yuma Valley", 32.7102, -114.7057, 37},
+}
+
+// Stations returns metadata for every known AZMET station.
+func Stations() []StationInfo {
+	list := make([]StationInfo, len(stations))
+	copy(list, stations)
+	return list
+}
+
+// LookupStation returns the metadata for station. ok is false when the
+// station is not in the table.
+func LookupStation(station WeatherStation) (info StationInfo, ok bool) {
+	for _, info := range stations {
+		if info.Station == station {
+			return info, true
+		}
+	}
+	return StationInfo{}, false
+}
+
+func (station WeatherStation) String() string {
+	if info, ok := LookupStation(station); ok {
+		return info.Name
+	}
+	return "WeatherStation(" + strconv.Itoa(int(station)) + ")"
+}
+
+// WriteStationMetadata writes the station table to w in the given format,
+// either "csv" or "json".
+func WriteStationMetadata(w io.Writer, format string) error {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"id", "name", "latitude", "longitude", "elevation"}); err != nil {
+			return err
+		}
+		for _, info := range stations {
+			record := []string{
+				strconv.Itoa(int(info.Station)),
+				info.Name,
+				strconv.FormatFloat(info.Latitude, 'f', -1, 64),
+				strconv.FormatFloat(info.Longitude, 'f', -1, 64),
+				strconv.FormatFloat(info.Elevation, 'f', -1, 64),
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		return json.NewEncoder(w).Encode(stations)
+	default:
+		return fmt.Errorf("unsupported station metadata format: %s", format)
+	}
+}
+
+// ParseStation resolves a station from its numeric ID or its name. Names are
+// matched case-insensitively and with or without spaces, so "Phoenix
+// Greenway", "phoenixgreenway" and "12" all name the same station.
+func ParseStation(s string) (WeatherStation, error) {
+	s = strings.TrimSpace(s)
+	if id, err := strconv.Atoi(s); err == nil {
+		for _, info := range stations {
+			if int(info.Station) == id {
+				return info.Station, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown weather station id: %d", id)
+	}
+	key := strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	for _, info := range stations {
+		if strings.ToLower(strings.ReplaceAll(info.Name, " ", "")) == key {
+			return info.Station, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weather station: %s", s)
+}
+
+// StationList is a flag.Value holding a comma-separated list of stations given
+// by name or ID.
+type StationList []WeatherStation
+
+func (l *StationList) String() string {
+	names := make([]string, len(*l))
+	for i, station := range *l {
+		names[i] = strconv.Itoa(int(station))
+	}
+	return strings.Join(names, ",")
+}
+
+// Set parses a comma-separated list, replacing any previous value. It fails
+// on the first entry that does not name a known station.
+func (l *StationList) Set(value string) error {
+	list := StationList{}
+	for _, part := range strings.Split(value, ",") {
+		station, err := ParseStation(part)
+		if err != nil {
+			return err
+		}
+		list = append(list, station)
+	}
+	*l = list
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+// inArizona reports whether a coordinate falls inside Arizona's bounding box.
+func inArizona(lat, lon float64) bool {
+	return lat > 31.3 && lat < 37 && lon > -114.9 && lon < -109
+}
+
+func TestWriteStationMetadataCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStationMetadata(&buf, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(stations)+1 {
+		t.Fatalf("got %d rows, want a header and %d stations", len(rows), len(stations))
+	}
+	for i, row := range rows[1:] {
+		want := stations[i]
+		if row[0] != strconv.Itoa(int(want.Station)) || row[1] != want.Name {
+			t.Errorf("row %d = %v, want station %d %q", i, row, want.Station, want.Name)
+		}
+		lat, _ := strconv.ParseFloat(row[2], 64)
+		lon, _ := strconv.ParseFloat(row[3], 64)
+		elev, _ := strconv.ParseFloat(row[4], 64)
+		if lat != want.Latitude || lon != want.Longitude || elev != want.Elevation {
+			t.Errorf("%s: coordinates %v, want %v", want.Name, row[2:], want)
+		}
+		if !inArizona(lat, lon) || elev <= 0 {
+			t.Errorf("%s: implausible coordinates %v", want.Name, row[2:])
+		}
+	}
+}
+
+func TestWriteStationMetadataJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStationMetadata(&buf, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []StationInfo
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(stations) {
+		t.Fatalf("got %d stations, want %d", len(decoded), len(stations))
+	}
+	for i, info := range decoded {
+		if info != stations[i] {
+			t.Errorf("station %d = %+v, want %+v", i, info, stations[i])
+		}
+		if !inArizona(info.Latitude, info.Longitude) || info.Elevation <= 0 {
+			t.Errorf("%s: implausible coordinates %+v", info.Name, info)
+		}
+	}
+
+	if err := WriteStationMetadata(&buf, "xml"); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+}
+
+func TestStationList(t *testing.T) {
+	var list StationList
+	if err := list.Set("Tucson, 12,phoenixencanto ,Yuma Valley"); err != nil {
+		t.Fatal(err)
+	}
+	want := StationList{Tucson, PhoenixGreenway, PhoenixEncanto, YumaValley}
+	if len(list) != len(want) {
+		t.Fatalf("list = %v, want %v", list, want)
+	}
+	for i := range want {
+		if list[i] != want[i] {
+			t.Errorf("entry %d = %v, want %v", i, list[i], want[i])
+		}
+	}
+	if got := list.String(); got != "1,12,15,2" {
+		t.Errorf("String() = %q, want \"1,12,15,2\"", got)
+	}
+
+	for _, bad := range []string{"Tucson,Atlantis", "Tucson,99", "Tucson,,Roll"} {
+		if err := list.Set(bad); err == nil {
+			t.Errorf("Set(%q) succeeded, want an error", bad)
+		}
+	}
+	if len(list) != len(want) {
+		t.Errorf("a failed Set replaced the list with %v", list)
+	}
+}
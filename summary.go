@@ -0,0 +1,71 @@
+package main
+
+// YearSummary holds headline figures for a year of hourly data. Temperatures
+// are in °C, precipitation and ET in mm, wind in m/s. Extremes and the mean
+// are MissingValue when no valid reading exists.
+type YearSummary struct {
+	Records            int
+	MinAirTemperature  float32
+	MaxAirTemperature  float32
+	MeanAirTemperature float32
+	Precipitation      float32
+	Evapotranspiration float32
+	MaxWindSpeed       float32
+	// Coverage is the fraction of numeric observations that are not missing.
+	Coverage float64
+}
+
+func SummarizeYear(data []HourlyWeatherData) YearSummary {
+	summary := YearSummary{
+		Records:            len(data),
+		MinAirTemperature:  MissingValue,
+		MaxAirTemperature:  MissingValue,
+		MeanAirTemperature: MissingValue,
+		MaxWindSpeed:       MissingValue,
+		Coverage:           fieldCoverage(data),
+	}
+
+	var sum float32
+	temps, gusts := 0, 0
+	for _, rec := range data {
+		if !IsMissing(rec.AirTemperature) {
+			if temps == 0 || rec.AirTemperature < summary.MinAirTemperature {
+				summary.MinAirTemperature = rec.AirTemperature
+			}
+			if temps == 0 || rec.AirTemperature > summary.MaxAirTemperature {
+				summary.MaxAirTemperature = rec.AirTemperature
+			}
+			sum += rec.AirTemperature
+			temps++
+		}
+		if !IsMissing(rec.WindSpeedMax) {
+			if gusts == 0 || rec.WindSpeedMax > summary.MaxWindSpeed {
+				summary.MaxWindSpeed = rec.WindSpeedMax
+			}
+			gusts++
+		}
+		if !IsMissing(rec.Precipitation) {
+			summary.Precipitation += rec.Precipitation
+		}
+		if !IsMissing(rec.Evapotranspiration) {
+			summary.Evapotranspiration += rec.Evapotranspiration
+		}
+	}
+	if temps > 0 {
+		summary.MeanAirTemperature = sum / float32(temps)
+	}
+
+	return summary
+}
+
+func (c *Client) DownloadSummary(station WeatherStation, year int) (YearSummary, error) {
+	data, err := c.DownloadHourlyData(station, year)
+	if err != nil {
+		return YearSummary{}, err
+	}
+	return SummarizeYear(data), nil
+}
+
+func DownloadSummary(station WeatherStation, year int) (YearSummary, error) {
+	return NewClient().DownloadSummary(station, year)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClientDownloadSummary(t *testing.T) {
+	files := map[string]string{
+		"1219rh.txt": "2019,1,1,5,30,1,0,0,15,15,1,1,180,10,4,0.1,1,5\n" +
+			"2019,1,2,999,30,1,0,2.5,15,15,1,1,180,10,12.5,0.2,1,5\n" +
+			"2019,1,3,-1,30,1,0,1,15,15,1,1,180,10,999,0.3,1,5\n",
+	}
+	client, _ := newStubClient(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC), files)
+
+	summary, err := client.DownloadSummary(PhoenixGreenway, 2019)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Records != 3 {
+		t.Errorf("Records = %d, want 3", summary.Records)
+	}
+	if summary.MinAirTemperature != -1 || summary.MaxAirTemperature != 5 || summary.MeanAirTemperature != 2 {
+		t.Errorf("temperatures = %v/%v/%v, want -1/5/2",
+			summary.MinAirTemperature, summary.MaxAirTemperature, summary.MeanAirTemperature)
+	}
+	if summary.Precipitation != 3.5 || summary.MaxWindSpeed != 12.5 {
+		t.Errorf("precipitation %v, gust %v; want 3.5 and 12.5", summary.Precipitation, summary.MaxWindSpeed)
+	}
+	if !approxEqual(float64(summary.Evapotranspiration), 0.6, 1e-6) {
+		t.Errorf("Evapotranspiration = %v, want 0.6", summary.Evapotranspiration)
+	}
+	if want := 1 - 2.0/45; !approxEqual(summary.Coverage, want, 1e-9) {
+		t.Errorf("Coverage = %v, want %v", summary.Coverage, want)
+	}
+
+	if _, err := client.DownloadSummary(PhoenixGreenway, 2018); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unpublished year: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import "time"
+
+// DefaultHumidityTrendThreshold is the relative humidity slope, in percentage
+// points per hour, beyond which a trend counts as rising or falling.
+const DefaultHumidityTrendThreshold float32 = 1
+
+// linearSlope fits y = a + b x by least squares and returns b. ok is false
+// when fewer than two distinct x values are supplied.
+func linearSlope(xs, ys []float64) (float64, bool) {
+	if len(xs) < 2 {
+		return 0, false
+	}
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	denom := n*sxx - sx*sx
+	if denom == 0 {
+		return 0, false
+	}
+	return (n*sxy - sx*sy) / denom, true
+}
+
+// hourlySeries returns the hours elapsed since start and the value of field
+// for each record where field is not missing.
+func hourlySeries(window []HourlyWeatherData, start time.Time, field func(HourlyWeatherData) float32) ([]float64, []float64) {
+	xs := make([]float64, 0, len(window))
+	ys := make([]float64, 0, len(window))
+	for _, rec := range window {
+		val := field(rec)
+		if IsMissing(val) {
+			continue
+		}
+		xs = append(xs, rec.Time.Sub(start).Hours())
+		ys = append(ys, float64(val))
+	}
+	return xs, ys
+}
+
+// RelativeHumidityTrend classifies the humidity trend across window as
+// "rising", "falling" or "steady" by comparing the slope of a linear fit, in
+// percentage points per hour, against threshold. It returns "unknown" when
+// fewer than two valid readings are available.
+func RelativeHumidityTrend(window []HourlyWeatherData, threshold float32) string {
+	if len(window) == 0 {
+		return "unknown"
+	}
+	xs, ys := hourlySeries(window, window[0].Time, func(rec HourlyWeatherData) float32 {
+		return rec.RelativeHumidity
+	})
+	slope, ok := linearSlope(xs, ys)
+	switch {
+	case !ok:
+		return "unknown"
+	case slope > float64(threshold):
+		return "rising"
+	case slope < -float64(threshold):
+		return "falling"
+	default:
+		return "steady"
+	}
+}
+
+// Thresholds used by FogRisk.
+const (
+	fogMaxDepression float32 = 2.5 // °C
+	fogMaxWindSpeed  float32 = 2.5 // m/s
+)
+
+// FogRisk examines a window of recent records for conditions that favour
+// radiation fog. closingRate is how fast the dewpoint depression (air
+// temperature minus dewpoint) is shrinking, in °C per hour from a linear
+// fit; it is negative when the gap is widening. Fog is flagged as likely
+// when the latest depression is within 2.5 °C, the gap is closing and the
+// latest wind is under 2.5 m/s. The dewpoint comes from DewpointHourAverage,
+// or ComputeDewpoint when that is missing.
+func FogRisk(window []HourlyWeatherData) (closingRate float32, likely bool) {
+	if len(window) == 0 {
+		return 0, false
+	}
+	depression := func(rec HourlyWeatherData) float32 {
+		if IsMissing(rec.AirTemperature) || (IsMissing(rec.DewpointHourAverage) && IsMissing(rec.RelativeHumidity)) {
+			return MissingValue
+		}
+		return rec.AirTemperature - rec.dewpoint()
+	}
+	xs, ys := hourlySeries(window, window[0].Time, depression)
+	slope, ok := linearSlope(xs, ys)
+	if !ok {
+		return 0, false
+	}
+	closingRate = float32(-slope)
+
+	latest := window[len(window)-1]
+	gap := depression(latest)
+	if IsMissing(gap) || IsMissing(latest.WindSpeedAverage) {
+		return closingRate, false
+	}
+	likely = gap <= fogMaxDepression && closingRate > 0 && latest.WindSpeedAverage < fogMaxWindSpeed
+	return closingRate, likely
+}
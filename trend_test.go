@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func humidityWindow(t *testing.T, values ...float32) []HourlyWeatherData {
+	t.Helper()
+	window := make([]HourlyWeatherData, len(values))
+	for i, rh := range values {
+		window[i] = testRecord(t, 2020, 200, i+1)
+		window[i].RelativeHumidity = rh
+	}
+	return window
+}
+
+func TestRelativeHumidityTrend(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float32
+		want   string
+	}{
+		{"rising", []float32{20, 25, 31, 35, 40}, "rising"},
+		{"falling", []float32{60, 52, 45, 41, 30}, "falling"},
+		{"flat with noise", []float32{40, 41, 39, 40, 41}, "steady"},
+		{"gap in readings", []float32{20, MissingValue, 30}, "rising"},
+		{"single reading", []float32{40, MissingValue}, "unknown"},
+	}
+	for _, tt := range tests {
+		window := humidityWindow(t, tt.values...)
+		if got := RelativeHumidityTrend(window, DefaultHumidityTrendThreshold); got != tt.want {
+			t.Errorf("%s: RelativeHumidityTrend = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := RelativeHumidityTrend(nil, DefaultHumidityTrendThreshold); got != "unknown" {
+		t.Errorf("empty window: RelativeHumidityTrend = %q, want \"unknown\"", got)
+	}
+}
+
+func TestFogRisk(t *testing.T) {
+	window := func(temps, dewpoints []float32, wind float32) []HourlyWeatherData {
+		recs := make([]HourlyWeatherData, len(temps))
+		for i := range temps {
+			recs[i] = testRecord(t, 2020, 350, i+1)
+			recs[i].AirTemperature = temps[i]
+			recs[i].DewpointHourAverage = dewpoints[i]
+			recs[i].WindSpeedAverage = wind
+		}
+		return recs
+	}
+
+	// Cooling toward a steady dewpoint closes the gap by 1.5 °C an hour.
+	converging := window([]float32{10, 8.5, 7, 5.5, 4}, []float32{2, 2, 2, 2, 2}, 1)
+	rate, likely := FogRisk(converging)
+	if !approxEqual(float64(rate), 1.5, 1e-4) || !likely {
+		t.Errorf("converging: rate %v likely %v, want 1.5 and true", rate, likely)
+	}
+
+	breezy := window([]float32{10, 8.5, 7, 5.5, 4}, []float32{2, 2, 2, 2, 2}, 4)
+	if _, likely := FogRisk(breezy); likely {
+		t.Errorf("breezy: fog flagged despite 4 m/s wind")
+	}
+
+	diverging := window([]float32{5, 6, 7, 8, 9}, []float32{4, 4, 4, 4, 4}, 1)
+	if rate, likely := FogRisk(diverging); rate >= 0 || likely {
+		t.Errorf("diverging: rate %v likely %v, want a negative rate and false", rate, likely)
+	}
+
+	computed := window([]float32{10, 8.5, 7, 5.5, 4}, []float32{MissingValue, MissingValue, MissingValue, MissingValue, MissingValue}, 1)
+	for i := range computed {
+		// Invert the Magnus formula for a dewpoint of 2 °C.
+		const a, b = 17.625, 243.04
+		temp := float64(computed[i].AirTemperature)
+		computed[i].RelativeHumidity = float32(100 * math.Exp(a*2/(b+2)-a*temp/(b+temp)))
+	}
+	if _, likely := FogRisk(computed); !likely {
+		t.Errorf("converging with computed dewpoints: fog not flagged")
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+// AZMET reports temperatures in degrees Celsius.
+
+func celsiusToFahrenheit(c float32) float32 {
+	return c*9/5 + 32
+}
+
+func fahrenheitToCelsius(f float32) float32 {
+	return (f - 32) * 5 / 9
+}
+
+// solarRadiationWatts converts an hourly SolarRadiation total in MJ/m² to the
+// mean irradiance over the hour in W/m².
+func solarRadiationWatts(mj float32) float64 {
+	return float64(mj) * 1e6 / 3600
+}
@@ -0,0 +1,50 @@
+package main
+
+import "math"
+
+type DailyWindDir struct {
+	Year int
+	Day  int
+	// Direction is the vector-mean direction in degrees from north, [0, 360).
+	Direction float32
+	// Hours is the number of hours with a valid direction.
+	Hours int
+}
+
+// DailyMeanWindDirection averages each day's hourly WindDirectionVector as
+// unit vectors so that, for example, 350° and 10° average to 0° rather than
+// 180°. When weighted is true each hour's vector is scaled by its
+// WindSpeedAverage, so calm hours count for less. Days without a valid
+// direction are omitted.
+func DailyMeanWindDirection(hourly []HourlyWeatherData, weighted bool) []DailyWindDir {
+	dirs := make([]DailyWindDir, 0)
+	for _, day := range groupByDay(hourly) {
+		var east, north float64
+		n := 0
+		for _, rec := range day {
+			if IsMissing(rec.WindDirectionVector) {
+				continue
+			}
+			w := 1.0
+			if weighted {
+				if IsMissing(rec.WindSpeedAverage) {
+					continue
+				}
+				w = float64(rec.WindSpeedAverage)
+			}
+			rad := float64(rec.WindDirectionVector) * math.Pi / 180
+			east += w * math.Sin(rad)
+			north += w * math.Cos(rad)
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		deg := math.Atan2(east, north) * 180 / math.Pi
+		if deg < 0 {
+			deg += 360
+		}
+		dirs = append(dirs, DailyWindDir{day[0].Year, day[0].Day, float32(deg), n})
+	}
+	return dirs
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+// angleDiff returns the smallest difference between two compass directions.
+func angleDiff(a, b float32) float32 {
+	d := a - b
+	for d > 180 {
+		d -= 360
+	}
+	for d < -180 {
+		d += 360
+	}
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func TestDailyMeanWindDirection(t *testing.T) {
+	readings := []struct{ dir, speed float32 }{
+		{350, 2},
+		{10, 2},
+		{340, 1},
+		{20, 5},
+		{MissingValue, 3},
+	}
+	var hourly []HourlyWeatherData
+	for i, r := range readings {
+		rec := testRecord(t, 2020, 60, i+1)
+		rec.WindDirectionVector, rec.WindSpeedAverage = r.dir, r.speed
+		hourly = append(hourly, rec)
+	}
+
+	// Unweighted the pairs cancel either side of north.
+	plain := DailyMeanWindDirection(hourly, false)
+	if len(plain) != 1 || plain[0].Hours != 4 {
+		t.Fatalf("unweighted = %+v, want one day of 4 hours", plain)
+	}
+	if angleDiff(plain[0].Direction, 0) > 0.01 {
+		t.Errorf("unweighted direction = %v, want 0", plain[0].Direction)
+	}
+
+	// The strong wind from 20° pulls the weighted mean east of north.
+	weighted := DailyMeanWindDirection(hourly, true)
+	if got := weighted[0].Direction; got < 5 || got > 15 {
+		t.Errorf("weighted direction = %v, want between 5 and 15", got)
+	}
+
+	westOfNorth := []HourlyWeatherData{hourly[0], hourly[2]}
+	if got := DailyMeanWindDirection(westOfNorth, false)[0].Direction; angleDiff(got, 345) > 0.01 {
+		t.Errorf("350° and 340° averaged to %v, want 345", got)
+	}
+}